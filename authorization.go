@@ -0,0 +1,116 @@
+package gohateoas
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Authorizer decides whether the user of a request may see a link, this way clients won't show buttons
+// for actions that would be denied anyway. The relation is the action the link was registered with.
+type Authorizer interface {
+	Authorize(ctx context.Context, object any, relation string, link LinkInfo) bool
+}
+
+// AuthorizerFunc allows a function to be used as an Authorizer
+type AuthorizerFunc func(ctx context.Context, object any, relation string, link LinkInfo) bool
+
+// Authorize calls the function
+func (a AuthorizerFunc) Authorize(ctx context.Context, object any, relation string, link LinkInfo) bool {
+	return a(ctx, object, relation, link)
+}
+
+// WithAuthorizer consults the authorizer for every link, links that are not authorized are left out. The
+// link is passed with its href expanded. Use InjectLinksContext to pass the context of the request.
+func WithAuthorizer(authorizer Authorizer) InjectOption {
+	// Every call gets its own handle, this way the decisions of different authorizers are cached separately
+	handle := &authorizerHandle{authorizer: authorizer}
+
+	return func(config *injectConfig) {
+		config.authorizer = handle
+	}
+}
+
+// authorizerHandle identifies an Authorizer passed to WithAuthorizer, since not every Authorizer is comparable
+type authorizerHandle struct {
+	authorizer Authorizer
+}
+
+// authorizationCacheKey is used to store an authorizationCache in a context.Context
+type authorizationCacheKey struct{}
+
+// authorizationDecision identifies a decision of an Authorizer in an authorizationCache
+type authorizationDecision struct {
+	authorizer *authorizerHandle
+	typeInfo   reflect.Type
+
+	// self is the expanded self link of the object, which tells objects of the same type apart
+	self string
+
+	relation string
+	method   string
+	href     string
+}
+
+// authorizationCache remembers the decisions of an Authorizer during a request
+type authorizationCache struct {
+	mutex     sync.Mutex
+	decisions map[authorizationDecision]bool
+}
+
+// ContextWithAuthorizationCache returns a copy of the context that remembers the decisions of an Authorizer,
+// so it is only consulted once for a link of an object with the same relation, method and expanded href. Objects
+// are told apart by the type and their self link, the decisions for objects without a self link are not
+// remembered. Use it once per request, Negotiate does this for you.
+func ContextWithAuthorizationCache(ctx context.Context) context.Context {
+	if authorizationCacheFrom(ctx) != nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, authorizationCacheKey{}, &authorizationCache{decisions: map[authorizationDecision]bool{}})
+}
+
+// authorizationCacheFrom returns the authorizationCache of the context, or nil if there is none
+func authorizationCacheFrom(ctx context.Context) *authorizationCache {
+	cache, _ := ctx.Value(authorizationCacheKey{}).(*authorizationCache)
+
+	return cache
+}
+
+// authorize asks the authorizer of the config whether the link may be shown, using the cache of
+// the context if there is one and the object has a self link.
+func (c *injectConfig) authorize(object any, self string, relation string, link LinkInfo) bool {
+	if c.authorizer == nil {
+		return true
+	}
+
+	cache := authorizationCacheFrom(c.ctx)
+	if cache == nil || self == "" {
+		return c.authorizer.authorizer.Authorize(c.ctx, object, relation, link)
+	}
+
+	decision := authorizationDecision{
+		authorizer: c.authorizer,
+		typeInfo:   typeKeyOf(object),
+		self:       self,
+		relation:   relation,
+		method:     link.Method,
+		href:       link.Href,
+	}
+
+	cache.mutex.Lock()
+	allowed, ok := cache.decisions[decision]
+	cache.mutex.Unlock()
+
+	if ok {
+		return allowed
+	}
+
+	allowed = c.authorizer.authorizer.Authorize(c.ctx, object, relation, link)
+
+	cache.mutex.Lock()
+	cache.decisions[decision] = allowed
+	cache.mutex.Unlock()
+
+	return allowed
+}
@@ -0,0 +1,231 @@
+package gohateoas
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// roleContextKey is used to store the role of a user in tests
+type roleContextKey struct{}
+
+// roleAuthorizer only allows admins to see links that are not GET requests
+var roleAuthorizer = AuthorizerFunc(func(ctx context.Context, _ any, _ string, link LinkInfo) bool {
+	return link.Method == http.MethodGet || ctx.Value(roleContextKey{}) == "admin"
+})
+
+func TestInjectLinksContext_LeavesOutUnauthorizedLinks(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		role     string
+		expected string
+	}{
+		"admin": {
+			role: "admin",
+			expected: `{"id":5,"_links":{
+				"self":{"method":"GET","href":"/bakeries/5","comment":""},
+				"delete":{"method":"DELETE","href":"/bakeries/5","comment":""}
+			}}`,
+		},
+		"visitor": {
+			role:     "visitor",
+			expected: `{"id":5,"_links":{"self":{"method":"GET","href":"/bakeries/5","comment":""}}}`,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+			RegisterOn(registry, bakery{}, Self("/bakeries/{id}", ""), Delete("/bakeries/{id}", ""))
+
+			ctx := context.WithValue(context.Background(), roleContextKey{}, testData.role)
+
+			// Act
+			result := InjectLinksContext(ctx, registry, bakery{ID: 5}, WithAuthorizer(roleAuthorizer))
+
+			// Assert
+			assert.JSONEq(t, testData.expected, string(result))
+		})
+	}
+}
+
+func TestInjectLinksWith_PassesExpandedLinkToAuthorizer(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, bakery{}, Self("/bakeries/{id}", "Get this bakery"))
+
+	var received []LinkInfo
+
+	authorizer := AuthorizerFunc(func(ctx context.Context, object any, relation string, link LinkInfo) bool {
+		assert.Equal(t, context.Background(), ctx)
+		assert.Equal(t, bakery{ID: 5}, object)
+		assert.Equal(t, "self", relation)
+
+		received = append(received, link)
+
+		return true
+	})
+
+	// Act
+	_ = InjectLinksWith(registry, bakery{ID: 5}, WithAuthorizer(authorizer))
+
+	// Assert
+	assert.Equal(t, []LinkInfo{{Method: http.MethodGet, Href: "/bakeries/5", Comment: "Get this bakery"}}, received)
+}
+
+func TestContextWithAuthorizationCache_RemembersDecisions(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		ctx           context.Context
+		expectedCalls int32
+	}{
+		"without cache": {
+			ctx:           context.Background(),
+			expectedCalls: 6,
+		},
+		"with cache": {
+			ctx:           ContextWithAuthorizationCache(context.Background()),
+			expectedCalls: 4,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+			RegisterOn(registry, cupcake{}, Self("/cupcakes/{id}", ""), Delete("/cupcakes/{id}", ""))
+
+			var calls int32
+
+			authorizer := AuthorizerFunc(func(context.Context, any, string, LinkInfo) bool {
+				atomic.AddInt32(&calls, 1)
+
+				return true
+			})
+
+			// The same cupcake twice, followed by another cupcake
+			object := []cupcake{{ID: 1}, {ID: 1}}
+
+			// Act
+			_ = InjectLinksContext(testData.ctx, registry, object, WithAuthorizer(authorizer))
+			_ = InjectLinksContext(testData.ctx, registry, cupcake{ID: 2}, WithAuthorizer(authorizer))
+
+			// Assert
+			assert.Equal(t, testData.expectedCalls, atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestContextWithAuthorizationCache_KeepsExistingCache(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	ctx := ContextWithAuthorizationCache(context.Background())
+
+	// Act
+	result := ContextWithAuthorizationCache(ctx)
+
+	// Assert
+	assert.Equal(t, ctx, result)
+}
+
+func TestNegotiate_PassesRequestContextToAuthorizer(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, &bakery{}, Self("/bakeries/{id}", ""), Delete("/bakeries/{id}", ""))
+
+	handler := Negotiate(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_ = Respond(writer, http.StatusOK, &bakery{ID: 5})
+	}), UsingRegistry(registry), WithInjectOptions(WithAuthorizer(roleAuthorizer)))
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request = request.WithContext(context.WithValue(request.Context(), roleContextKey{}, "admin"))
+
+	recorder := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(recorder, request)
+
+	// Assert
+	expected := `{"id":5,"_links":{
+		"self":{"method":"GET","href":"/bakeries/5","comment":""},
+		"delete":{"method":"DELETE","href":"/bakeries/5","comment":""}
+	}}`
+	assert.JSONEq(t, expected, recorder.Body.String())
+}
+
+func TestContextWithAuthorizationCache_DecidesPerObject(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, cupcake{}, Self("/cupcakes/{id}", ""), Index("/cupcakes", ""))
+
+	authorizer := AuthorizerFunc(func(_ context.Context, object any, _ string, _ LinkInfo) bool {
+		cake, _ := object.(cupcake)
+
+		return cake.ID == 1
+	})
+
+	ctx := ContextWithAuthorizationCache(context.Background())
+
+	// Act
+	result := InjectLinksContext(ctx, registry, []cupcake{{ID: 1}, {ID: 2}}, WithAuthorizer(authorizer))
+
+	// Assert
+	expected := `[
+		{"id":1,"name":"","bakery":null,"_links":{"self":{"method":"GET","href":"/cupcakes/1","comment":""},"index":{"method":"GET","href":"/cupcakes","comment":""}}},
+		{"id":2,"name":"","bakery":null}
+	]`
+	assert.JSONEq(t, expected, string(result))
+}
+
+func TestContextWithAuthorizationCache_DecidesPerAuthorizer(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, cupcake{}, Self("/cupcakes/{id}", ""))
+
+	allowAll := AuthorizerFunc(func(context.Context, any, string, LinkInfo) bool { return true })
+	denyAll := AuthorizerFunc(func(context.Context, any, string, LinkInfo) bool { return false })
+
+	ctx := ContextWithAuthorizationCache(context.Background())
+
+	// Act
+	allowed := InjectLinksContext(ctx, registry, cupcake{ID: 1}, WithAuthorizer(allowAll))
+	denied := InjectLinksContext(ctx, registry, cupcake{ID: 1}, WithAuthorizer(denyAll))
+
+	// Assert
+	assert.JSONEq(t, `{"id":1,"name":"","bakery":null,"_links":{"self":{"method":"GET","href":"/cupcakes/1","comment":""}}}`, string(allowed))
+	assert.JSONEq(t, `{"id":1,"name":"","bakery":null}`, string(denied))
+}
+
+func TestContextWithAuthorizationCache_IgnoresObjectsWithoutSelfLink(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, cupcake{}, Index("/cupcakes", ""))
+
+	var calls int32
+
+	authorizer := AuthorizerFunc(func(context.Context, any, string, LinkInfo) bool {
+		atomic.AddInt32(&calls, 1)
+
+		return true
+	})
+
+	// Act
+	_ = InjectLinksContext(ContextWithAuthorizationCache(context.Background()), registry, []cupcake{{ID: 1}, {ID: 1}}, WithAuthorizer(authorizer))
+
+	// Assert
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,109 @@
+package gohateoas
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// WithBaseURL prefixes relative hrefs with the base url, like https://example.com/prefix, to make
+// them absolute. Hrefs that already have a scheme or a host are left alone.
+func WithBaseURL(baseURL string) InjectOption {
+	return func(config *injectConfig) {
+		config.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// schemeRegex matches hrefs that start with a scheme, like https: or mailto:
+var schemeRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+
+// absoluteHref prefixes the href with the base url if it is relative
+func absoluteHref(baseURL string, href string) string {
+	if baseURL == "" || schemeRegex.MatchString(href) || strings.HasPrefix(href, "//") {
+		return href
+	}
+
+	return baseURL + "/" + strings.TrimPrefix(href, "/")
+}
+
+// BaseURL derives the base url of the api from the request, like https://example.com/prefix. The scheme
+// and host are taken from the RFC 7239 Forwarded header, the X-Forwarded-Proto and X-Forwarded-Host headers
+// or the request itself, in that order. The prefix is taken from X-Forwarded-Prefix.
+//
+// These headers are set by the client if there is no proxy in between, so only use this if your
+// proxies overwrite them.
+func BaseURL(request *http.Request) string {
+	forwarded := parseForwarded(request.Header.Get("Forwarded"))
+
+	scheme := forwarded["proto"]
+	if scheme == "" {
+		scheme = firstHeaderValue(request.Header.Get("X-Forwarded-Proto"))
+	}
+
+	if scheme == "" {
+		scheme = "http"
+
+		if request.TLS != nil {
+			scheme = "https"
+		}
+	}
+
+	host := forwarded["host"]
+	if host == "" {
+		host = firstHeaderValue(request.Header.Get("X-Forwarded-Host"))
+	}
+
+	if host == "" {
+		host = request.Host
+	}
+
+	prefix := strings.Trim(firstHeaderValue(request.Header.Get("X-Forwarded-Prefix")), "/")
+	if prefix != "" {
+		prefix = "/" + prefix
+	}
+
+	return strings.ToLower(scheme) + "://" + host + prefix
+}
+
+// firstHeaderValue returns the first value of a comma-separated header, added by the proxy closest to the client
+func firstHeaderValue(value string) string {
+	first, _, _ := strings.Cut(value, ",")
+
+	return strings.TrimSpace(first)
+}
+
+// parseForwarded returns the parameters of the first element of an RFC 7239 Forwarded header, like
+// proto and host, with lowercase names and unquoted values.
+func parseForwarded(value string) map[string]string {
+	params := map[string]string{}
+
+	// Commas separate elements, but they may also appear in quoted values
+	end, quoted := len(value), false
+
+	for i := 0; i < len(value); i++ {
+		if value[i] == '"' {
+			quoted = !quoted
+		}
+
+		if value[i] == ',' && !quoted {
+			end = i
+
+			break
+		}
+	}
+
+	for _, pair := range strings.Split(value[:end], ";") {
+		name, paramValue, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+
+		if strings.HasPrefix(paramValue, `"`) && strings.HasSuffix(paramValue, `"`) && len(paramValue) >= 2 {
+			paramValue = strings.ReplaceAll(paramValue[1:len(paramValue)-1], `\"`, `"`)
+		}
+
+		params[strings.ToLower(name)] = paramValue
+	}
+
+	return params
+}
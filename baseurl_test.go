@@ -0,0 +1,188 @@
+package gohateoas
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseURL_ReturnsExpectedURL(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		headers  map[string]string
+		tls      bool
+		expected string
+	}{
+		"plain request": {
+			expected: "http://example.com",
+		},
+		"tls": {
+			tls:      true,
+			expected: "https://example.com",
+		},
+		"x-forwarded headers": {
+			headers: map[string]string{
+				"X-Forwarded-Proto":  "https",
+				"X-Forwarded-Host":   "api.example.org",
+				"X-Forwarded-Prefix": "/bakery/",
+			},
+			expected: "https://api.example.org/bakery",
+		},
+		"multiple proxies": {
+			headers: map[string]string{
+				"X-Forwarded-Proto": "https, http",
+				"X-Forwarded-Host":  "api.example.org, internal:8080",
+			},
+			expected: "https://api.example.org",
+		},
+		"prefix without slashes": {
+			headers:  map[string]string{"X-Forwarded-Prefix": "bakery"},
+			expected: "http://example.com/bakery",
+		},
+		"forwarded": {
+			headers:  map[string]string{"Forwarded": `for=192.0.2.60;proto=HTTPS;host="api.example.org:8443"`},
+			expected: "https://api.example.org:8443",
+		},
+		"forwarded with multiple elements": {
+			headers:  map[string]string{"Forwarded": `host="a.example.org";proto=https, host=b.example.org;proto=http`},
+			expected: "https://a.example.org",
+		},
+		"forwarded takes precedence": {
+			headers: map[string]string{
+				"Forwarded":         "proto=https;host=a.example.org",
+				"X-Forwarded-Proto": "http",
+				"X-Forwarded-Host":  "b.example.org",
+			},
+			expected: "https://a.example.org",
+		},
+		"forwarded without host": {
+			headers: map[string]string{
+				"Forwarded":        "for=192.0.2.60;proto=https",
+				"X-Forwarded-Host": "b.example.org",
+			},
+			expected: "https://b.example.org",
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			request := httptest.NewRequest(http.MethodGet, "http://example.com/cupcakes", nil)
+
+			for header, value := range testData.headers {
+				request.Header.Set(header, value)
+			}
+
+			if testData.tls {
+				request.TLS = &tls.ConnectionState{}
+			}
+
+			// Act
+			result := BaseURL(request)
+
+			// Assert
+			assert.Equal(t, testData.expected, result)
+		})
+	}
+}
+
+func TestInjectLinksWith_WithBaseURLMakesLinksAbsolute(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, bakery{},
+		Self("/api/v1/bakeries/{id}", ""),
+		Index("api/v1/bakeries", ""),
+		Custom("docs", LinkInfo{Method: http.MethodGet, Href: "https://docs.example.org/bakeries"}),
+		Custom("cdn", LinkInfo{Method: http.MethodGet, Href: "//cdn.example.org/bakeries/{id}.png"}))
+
+	// Act
+	result := InjectLinksWith(registry, bakery{ID: 5}, WithBaseURL("https://example.com/prefix/"))
+
+	// Assert
+	expected := `{"id":5,"_links":{
+		"self":{"method":"GET","href":"https://example.com/prefix/api/v1/bakeries/5","comment":""},
+		"index":{"method":"GET","href":"https://example.com/prefix/api/v1/bakeries","comment":""},
+		"docs":{"method":"GET","href":"https://docs.example.org/bakeries","comment":""},
+		"cdn":{"method":"GET","href":"//cdn.example.org/bakeries/5.png","comment":""}
+	}}`
+	assert.JSONEq(t, expected, string(result))
+}
+
+func TestNegotiate_WithBaseURLFromRequestMakesLinksAbsolute(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, &bakery{}, Self("/api/v1/bakeries/{id}", ""))
+
+	handler := Negotiate(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_ = Respond(writer, http.StatusOK, &bakery{ID: 5})
+	}), UsingRegistry(registry), WithBaseURLFromRequest(), WithLinkHeader())
+
+	request := httptest.NewRequest(http.MethodGet, "http://internal:8080/api/v1/bakeries/5", nil)
+	request.Header.Set("Forwarded", "proto=https;host=example.com")
+	request.Header.Set("X-Forwarded-Prefix", "/bakery")
+
+	recorder := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(recorder, request)
+
+	// Assert
+	assert.Equal(t, `<https://example.com/bakery/api/v1/bakeries/5>; rel="self"`, recorder.Header().Get("Link"))
+	assert.JSONEq(t, `{"id":5,"_links":{"self":{"method":"GET","href":"https://example.com/bakery/api/v1/bakeries/5","comment":""}}}`, recorder.Body.String())
+}
+
+func TestWithRegistryBaseURL_MakesLinksAbsolute(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		options  []InjectOption
+		expected string
+	}{
+		"registry default": {
+			expected: "https://example.com/prefix/api/v1/bakeries/5",
+		},
+		"overridden per call": {
+			options:  []InjectOption{WithBaseURL("https://example.org")},
+			expected: "https://example.org/api/v1/bakeries/5",
+		},
+		"disabled per call": {
+			options:  []InjectOption{WithBaseURL("")},
+			expected: "/api/v1/bakeries/5",
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry(WithRegistryBaseURL("https://example.com/prefix/"))
+			RegisterOn(registry, bakery{}, Self("/api/v1/bakeries/{id}", ""))
+
+			// Act
+			result := InjectLinksWith(registry, bakery{ID: 5}, testData.options...)
+
+			// Assert
+			assert.JSONEq(t, `{"id":5,"_links":{"self":{"method":"GET","href":"`+testData.expected+`","comment":""}}}`, string(result))
+		})
+	}
+}
+
+func TestWithRegistryBaseURL_IsKeptByClone(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry(WithRegistryBaseURL("https://example.com"))
+	RegisterOn(registry, bakery{}, Self("/bakeries/{id}", ""))
+
+	// Act
+	result := LinkHeader(registry.Prefixed("/api/v2").Clone(), bakery{ID: 5})
+
+	// Assert
+	assert.Equal(t, `<https://example.com/api/v2/bakeries/5>; rel="self"`, result)
+}
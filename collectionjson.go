@@ -0,0 +1,121 @@
+package gohateoas
+
+import (
+	"net/http"
+	"sort"
+)
+
+// AsCollectionJSON renders registered structs as Collection+JSON (application/vnd.collection+json) items
+// with a href, data and links. The document is wrapped in a collection object, of which the href is
+// taken from the index link of the first item. Only links with the GET method are rendered.
+func AsCollectionJSON() InjectOption {
+	return func(config *injectConfig) {
+		config.renderer = collectionJSONRenderer{}
+	}
+}
+
+// collectionJSONData is a single property of an item as described in the Collection+JSON specification
+type collectionJSONData struct {
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+}
+
+// collectionJSONLink is a link as described in the Collection+JSON specification
+type collectionJSONLink struct {
+	Rel    string `json:"rel"`
+	Href   string `json:"href"`
+	Name   string `json:"name,omitempty"`
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// collectionJSONRenderer renders resources according to the Collection+JSON specification
+type collectionJSONRenderer struct{}
+
+func (collectionJSONRenderer) RenderLinks(resource *Resource) {
+	if !resource.Registered {
+		return
+	}
+
+	// Sort the keys to make sure the output is always in the same order
+	keys := make([]string, 0, len(resource.Result))
+	for key := range resource.Result {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	data := make([]collectionJSONData, 0, len(keys))
+
+	// Replace the contents of the original map, that way the parent's reference stays intact
+	for _, key := range keys {
+		data = append(data, collectionJSONData{Name: key, Value: resource.Result[key]})
+		delete(resource.Result, key)
+	}
+
+	resource.Result["data"] = data
+
+	if self, ok := resource.Links["self"]; ok {
+		resource.Result["href"] = self.Href
+	}
+
+	if links := collectionJSONLinksOf(resource.Links); len(links) > 0 {
+		resource.Result["links"] = links
+	}
+}
+
+func (collectionJSONRenderer) RenderDocument(result any) any {
+	items, ok := result.([]any)
+	if !ok {
+		items = []any{result}
+	}
+
+	collection := map[string]any{
+		"version": "1.0",
+		"items":   items,
+	}
+
+	if len(items) > 0 {
+		if item, ok := items[0].(map[string]any); ok {
+			links, _ := item["links"].([]collectionJSONLink)
+
+			for _, link := range links {
+				if link.Rel == "index" {
+					collection["href"] = link.Href
+
+					break
+				}
+			}
+		}
+	}
+
+	return map[string]any{"collection": collection}
+}
+
+// collectionJSONLinksOf converts all GET links, except for self, to Collection+JSON links
+func collectionJSONLinksOf(links map[string]LinkInfo) []collectionJSONLink {
+	actions := sortedActions(links)
+
+	var result []collectionJSONLink
+
+	for _, action := range actions {
+		linkInfo := links[action]
+
+		if action == "self" || (linkInfo.Method != "" && linkInfo.Method != http.MethodGet) {
+			continue
+		}
+
+		relation := linkInfo.Rel
+		if relation == "" {
+			relation = action
+		}
+
+		result = append(result, collectionJSONLink{
+			Rel:    relation,
+			Href:   linkInfo.Href,
+			Name:   linkInfo.Name,
+			Prompt: linkInfo.Comment,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,103 @@
+package gohateoas
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInjectLinksWith_AsCollectionJSONCreatesExpectedJson(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		input    any
+		expected map[string]any
+	}{
+		"single object": {
+			input: &cupcake{ID: 1, Name: "a"},
+			expected: map[string]any{
+				"collection": map[string]any{
+					"version": "1.0",
+					"href":    "/api/v1/cupcakes",
+					"items": []any{
+						map[string]any{
+							"href": "/api/v1/cupcakes/1",
+							"data": []any{
+								map[string]any{"name": "bakery", "value": nil},
+								map[string]any{"name": "id", "value": float64(1)},
+								map[string]any{"name": "name", "value": "a"},
+							},
+							"links": []any{
+								map[string]any{"rel": "index", "href": "/api/v1/cupcakes", "prompt": "all cupcakes"},
+							},
+						},
+					},
+				},
+			},
+		},
+		"slice": {
+			input: []cupcake{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}},
+			expected: map[string]any{
+				"collection": map[string]any{
+					"version": "1.0",
+					"href":    "/api/v1/cupcakes",
+					"items": []any{
+						map[string]any{
+							"href": "/api/v1/cupcakes/1",
+							"data": []any{
+								map[string]any{"name": "bakery", "value": nil},
+								map[string]any{"name": "id", "value": float64(1)},
+								map[string]any{"name": "name", "value": "a"},
+							},
+							"links": []any{
+								map[string]any{"rel": "index", "href": "/api/v1/cupcakes", "prompt": "all cupcakes"},
+							},
+						},
+						map[string]any{
+							"href": "/api/v1/cupcakes/2",
+							"data": []any{
+								map[string]any{"name": "bakery", "value": nil},
+								map[string]any{"name": "id", "value": float64(2)},
+								map[string]any{"name": "name", "value": "b"},
+							},
+							"links": []any{
+								map[string]any{"rel": "index", "href": "/api/v1/cupcakes", "prompt": "all cupcakes"},
+							},
+						},
+					},
+				},
+			},
+		},
+		"empty slice": {
+			input: []cupcake{},
+			expected: map[string]any{
+				"collection": map[string]any{
+					"version": "1.0",
+					"items":   []any{},
+				},
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+
+			RegisterOn(registry, &cupcake{},
+				Self("/api/v1/cupcakes/{id}", "get itself"),
+				Index("/api/v1/cupcakes", "all cupcakes"),
+				Delete("/api/v1/cupcakes/{id}", "delete it"))
+
+			// Act
+			result := InjectLinksWith(registry, testData.input, AsCollectionJSON())
+
+			// Assert
+			var mapResult map[string]any
+			_ = json.Unmarshal(result, &mapResult)
+			assert.Equal(t, testData.expected, mapResult)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package gohateoas
+
+import (
+	"reflect"
+	"strings"
+)
+
+// MergePolicy decides what happens when a link is registered on the same type and action in both
+// registries of a merge.
+type MergePolicy int
+
+const (
+	// FailOnConflict results in a MergeConflictError, nothing is merged
+	FailOnConflict MergePolicy = iota
+
+	// OverrideOnConflict replaces the link with the one of the merged registry
+	OverrideOnConflict
+
+	// KeepFirstOnConflict keeps the link that was already registered
+	KeepFirstOnConflict
+)
+
+// Clone returns a new registry with the same links and options, registrations on either registry don't
+// affect the other.
+func (l *LinkRegistry) Clone() *LinkRegistry {
+	clone := l.withoutLinks()
+
+	// The links of a type are never modified, so they can be shared
+	clone.update(func(links map[reflect.Type]map[string]LinkInfo) {
+		for typeInfo, typeLinks := range l.snapshot() {
+			links[typeInfo] = typeLinks
+		}
+	})
+
+	return clone
+}
+
+// Merge adds the links of the other registry to this one. Links of types that are registered in both
+// are merged action by action, the policy decides what happens to actions that are registered in both.
+// With FailOnConflict a MergeConflictError is returned and nothing is merged.
+func (l *LinkRegistry) Merge(other *LinkRegistry, policy MergePolicy) error {
+	var err error
+
+	otherLinks := other.snapshot()
+
+	l.update(func(links map[reflect.Type]map[string]LinkInfo) {
+		if policy == FailOnConflict {
+			if err = findConflict(links, otherLinks); err != nil {
+				return
+			}
+		}
+
+		for typeInfo, typeLinks := range otherLinks {
+			merged := make(map[string]LinkInfo, len(links[typeInfo])+len(typeLinks))
+			for action, linkInfo := range links[typeInfo] {
+				merged[action] = linkInfo
+			}
+
+			for action, linkInfo := range typeLinks {
+				if _, exists := merged[action]; exists && policy == KeepFirstOnConflict {
+					continue
+				}
+
+				merged[action] = linkInfo
+			}
+
+			links[typeInfo] = merged
+		}
+	})
+
+	return err
+}
+
+// findConflict returns a MergeConflictError for the first action that is registered on the same type in both
+func findConflict(links map[reflect.Type]map[string]LinkInfo, otherLinks map[reflect.Type]map[string]LinkInfo) error {
+	for typeInfo, typeLinks := range otherLinks {
+		for action := range typeLinks {
+			if _, exists := links[typeInfo][action]; exists {
+				return &MergeConflictError{Type: typeInfo.String(), Action: action}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Prefixed returns a copy of the registry of which every relative href is prefixed with the path, like
+// /api/v2. This allows modules to register their routes relative to where they're mounted. Hrefs that
+// have a scheme or a host are left alone, computed hrefs are prefixed after they're computed.
+func (l *LinkRegistry) Prefixed(path string) *LinkRegistry {
+	prefixed := l.withoutLinks()
+
+	prefixed.update(func(links map[reflect.Type]map[string]LinkInfo) {
+		for typeInfo, typeLinks := range l.snapshot() {
+			prefixedLinks := make(map[string]LinkInfo, len(typeLinks))
+
+			for action, linkInfo := range typeLinks {
+				prefixedLinks[action] = prefixLink(path, linkInfo)
+			}
+
+			links[typeInfo] = prefixedLinks
+		}
+	})
+
+	return prefixed
+}
+
+// prefixLink prefixes the href of the link with the path, including the href it computes
+func prefixLink(path string, linkInfo LinkInfo) LinkInfo {
+	linkInfo.Href = prefixHref(path, linkInfo.Href)
+
+	if compute := linkInfo.compute; compute != nil {
+		linkInfo.compute = func(object any) (LinkInfo, bool) {
+			computed, ok := compute(object)
+			computed.Href = prefixHref(path, computed.Href)
+
+			return computed, ok
+		}
+	}
+
+	return linkInfo
+}
+
+// prefixHref prefixes the href with the path if it is relative
+func prefixHref(path string, href string) string {
+	path = strings.Trim(path, "/")
+
+	if path == "" || schemeRegex.MatchString(href) || strings.HasPrefix(href, "//") {
+		return href
+	}
+
+	return "/" + path + "/" + strings.TrimPrefix(href, "/")
+}
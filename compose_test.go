@@ -0,0 +1,183 @@
+package gohateoas
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkRegistry_Clone_CopiesLinks(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, cupcake{}, Self("/cupcakes/{id}", ""))
+
+	// Act
+	clone := registry.Clone()
+
+	RegisterOn(clone, bakery{}, Self("/bakeries/{id}", ""))
+	RegisterOn(registry, cupcake{}, Index("/cupcakes", ""))
+
+	// Assert
+	assert.Equal(t, map[string]LinkInfo{"self": {Method: http.MethodGet, Href: "/cupcakes/{id}"}}, clone.Links(cupcake{}))
+	assert.Equal(t, map[string]LinkInfo{"index": {Method: http.MethodGet, Href: "/cupcakes"}}, registry.Links(cupcake{}))
+	assert.Nil(t, registry.Links(bakery{}))
+}
+
+func TestLinkRegistry_Merge_MergesLinksPerAction(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		policy   MergePolicy
+		expected map[string]LinkInfo
+	}{
+		"override": {
+			policy: OverrideOnConflict,
+			expected: map[string]LinkInfo{
+				"self":  {Method: http.MethodGet, Href: "/v2/cupcakes/{id}"},
+				"index": {Method: http.MethodGet, Href: "/cupcakes"},
+				"post":  {Method: http.MethodPost, Href: "/cupcakes"},
+			},
+		},
+		"keep first": {
+			policy: KeepFirstOnConflict,
+			expected: map[string]LinkInfo{
+				"self":  {Method: http.MethodGet, Href: "/cupcakes/{id}"},
+				"index": {Method: http.MethodGet, Href: "/cupcakes"},
+				"post":  {Method: http.MethodPost, Href: "/cupcakes"},
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+			RegisterOn(registry, cupcake{}, Self("/cupcakes/{id}", ""), Index("/cupcakes", ""))
+
+			other := NewLinkRegistry()
+			RegisterOn(other, cupcake{}, Self("/v2/cupcakes/{id}", ""), Post("/cupcakes", ""))
+			RegisterOn(other, bakery{}, Self("/bakeries/{id}", ""))
+
+			// Act
+			err := registry.Merge(other, testData.policy)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expected, registry.Links(cupcake{}))
+			assert.Equal(t, map[string]LinkInfo{"self": {Method: http.MethodGet, Href: "/bakeries/{id}"}}, registry.Links(bakery{}))
+		})
+	}
+}
+
+func TestLinkRegistry_Merge_FailsOnConflict(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, cupcake{}, Self("/cupcakes/{id}", ""))
+
+	other := NewLinkRegistry()
+	RegisterOn(other, cupcake{}, Self("/v2/cupcakes/{id}", ""))
+	RegisterOn(other, bakery{}, Self("/bakeries/{id}", ""))
+
+	// Act
+	err := registry.Merge(other, FailOnConflict)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrMergeConflict)
+	assert.Equal(t, &MergeConflictError{Type: "gohateoas.cupcake", Action: "self"}, err)
+	assert.Equal(t, map[string]LinkInfo{"self": {Method: http.MethodGet, Href: "/cupcakes/{id}"}}, registry.Links(cupcake{}))
+	assert.Nil(t, registry.Links(bakery{}))
+}
+
+func TestLinkRegistry_Merge_AddsDistinctActionsWithoutConflict(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, cupcake{}, Self("/cupcakes/{id}", ""))
+
+	other := NewLinkRegistry()
+	RegisterOn(other, cupcake{}, Index("/cupcakes", ""))
+
+	// Act
+	err := registry.Merge(other, FailOnConflict)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, registry.Links(cupcake{}), 2)
+	assert.Len(t, other.Links(cupcake{}), 1)
+}
+
+func TestLinkRegistry_Prefixed_PrefixesRelativeHrefs(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		path     string
+		href     string
+		expected string
+	}{
+		"absolute path":    {path: "/api/v2", href: "/cupcakes/{id}", expected: "/api/v2/cupcakes/{id}"},
+		"relative path":    {path: "/api/v2", href: "cupcakes/{id}", expected: "/api/v2/cupcakes/{id}"},
+		"trailing slash":   {path: "api/v2/", href: "/cupcakes/{id}", expected: "/api/v2/cupcakes/{id}"},
+		"empty path":       {path: "", href: "/cupcakes/{id}", expected: "/cupcakes/{id}"},
+		"url with scheme":  {path: "/api/v2", href: "https://example.com/cupcakes", expected: "https://example.com/cupcakes"},
+		"url without host": {path: "/api/v2", href: "//example.com/cupcakes", expected: "//example.com/cupcakes"},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+			RegisterOn(registry, cupcake{}, Self(testData.href, ""))
+
+			// Act
+			result := registry.Prefixed(testData.path)
+
+			// Assert
+			assert.Equal(t, map[string]LinkInfo{"self": {Method: http.MethodGet, Href: testData.expected}}, result.Links(cupcake{}))
+			assert.Equal(t, map[string]LinkInfo{"self": {Method: http.MethodGet, Href: testData.href}}, registry.Links(cupcake{}))
+		})
+	}
+}
+
+func TestLinkRegistry_Prefixed_PrefixesComputedHrefs(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterFor(registry, SelfFunc(func(object cupcake) string {
+		return fmt.Sprintf("/cupcakes/%d", object.ID)
+	}, ""))
+
+	// Act
+	result := InjectLinks(registry.Prefixed("/api/v2"), cupcake{ID: 5})
+
+	// Assert
+	assert.JSONEq(t, `{"id":5,"name":"","bakery":null,"_links":{"self":{"method":"GET","href":"/api/v2/cupcakes/5","comment":""}}}`, string(result))
+}
+
+func TestLinkRegistry_Merge_MountsModules(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	cupcakeModule := NewLinkRegistry()
+	RegisterOn(cupcakeModule, cupcake{}, Self("/cupcakes/{id}", ""))
+
+	bakeryModule := NewLinkRegistry()
+	RegisterOn(bakeryModule, bakery{}, Self("/bakeries/{id}", ""))
+
+	registry := NewLinkRegistry()
+
+	// Act
+	errs := []error{
+		registry.Merge(cupcakeModule.Prefixed("/api/v2"), FailOnConflict),
+		registry.Merge(bakeryModule.Prefixed("/api/v1"), FailOnConflict),
+	}
+
+	// Assert
+	assert.Equal(t, []error{nil, nil}, errs)
+	assert.JSONEq(t, `{"id":1,"name":"","bakery":{"id":2,"_links":{"self":{"method":"GET","href":"/api/v1/bakeries/2","comment":""}}},"_links":{"self":{"method":"GET","href":"/api/v2/cupcakes/1","comment":""}}}`,
+		string(InjectLinks(registry, cupcake{ID: 1, Bakery: &bakery{ID: 2}})))
+}
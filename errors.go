@@ -0,0 +1,126 @@
+package gohateoas
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotAStruct is returned when an object is expected to be a struct
+var ErrNotAStruct = errors.New("object is not a struct")
+
+// ErrUnresolvedToken is matched by an UnresolvedTokenError, use errors.Is to check for it
+var ErrUnresolvedToken = errors.New("unresolved token")
+
+// UnresolvedTokenError is returned if a token in the href of a link could not be replaced
+// because the object has no value for it.
+type UnresolvedTokenError struct {
+	// Type is the name of the type the link is registered on
+	Type string
+
+	// Action is the action the link is registered with, like self
+	Action string
+
+	// Href is the href of the link
+	Href string
+
+	// Token is the token that could not be resolved, like id
+	Token string
+}
+
+func (e *UnresolvedTokenError) Error() string {
+	return fmt.Sprintf("%s: {%s} in %s link %q of %s", ErrUnresolvedToken, e.Token, e.Action, e.Href, e.Type)
+}
+
+// Unwrap allows errors.Is to match ErrUnresolvedToken
+func (e *UnresolvedTokenError) Unwrap() error {
+	return ErrUnresolvedToken
+}
+
+// ErrMergeConflict is matched by a MergeConflictError, use errors.Is to check for it
+var ErrMergeConflict = errors.New("merge conflict")
+
+// MergeConflictError is returned if a link is registered on the same type and action in both
+// registries of a merge.
+type MergeConflictError struct {
+	// Type is the name of the type the link is registered on
+	Type string
+
+	// Action is the action the link is registered with, like self
+	Action string
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("%s: %s link of %s is registered in both registries", ErrMergeConflict, e.Action, e.Type)
+}
+
+// Unwrap allows errors.Is to match ErrMergeConflict
+func (e *MergeConflictError) Unwrap() error {
+	return ErrMergeConflict
+}
+
+// ErrDuplicateLink is matched by a DuplicateLinkError, use errors.Is to check for it
+var ErrDuplicateLink = errors.New("duplicate link")
+
+// DuplicateLinkError is returned if a link is added with an action that is already registered on the
+// type, and the registry was created with RejectDuplicateLinks.
+type DuplicateLinkError struct {
+	// Type is the name of the type the link is registered on
+	Type string
+
+	// Action is the action that is registered more than once, like self
+	Action string
+}
+
+func (e *DuplicateLinkError) Error() string {
+	return fmt.Sprintf("%s: %s link of %s is already registered", ErrDuplicateLink, e.Action, e.Type)
+}
+
+// Unwrap allows errors.Is to match ErrDuplicateLink
+func (e *DuplicateLinkError) Unwrap() error {
+	return ErrDuplicateLink
+}
+
+// EncodingError is returned if the object could not be encoded to or decoded from json, it
+// wraps the original error of encoding/json.
+type EncodingError struct {
+	Err error
+}
+
+func (e *EncodingError) Error() string {
+	return fmt.Sprintf("failed to encode object: %s", e.Err)
+}
+
+// Unwrap returns the original error of encoding/json
+func (e *EncodingError) Unwrap() error {
+	return e.Err
+}
+
+// UnresolvedTokenPolicy decides what happens to links with tokens that can't be resolved
+type UnresolvedTokenPolicy int
+
+const (
+	// KeepUnresolvedTokens leaves the token in the href, this is the default of InjectLinks
+	KeepUnresolvedTokens UnresolvedTokenPolicy = iota
+
+	// FailOnUnresolvedTokens results in an UnresolvedTokenError, this is the default of MarshalWithLinks.
+	// Since InjectLinks does not return errors, it returns nil instead.
+	FailOnUnresolvedTokens
+
+	// DropUnresolvedLinks removes links with unresolved tokens from the output
+	DropUnresolvedLinks
+)
+
+// OnUnresolvedToken sets the policy for links with tokens that can't be resolved
+func OnUnresolvedToken(policy UnresolvedTokenPolicy) InjectOption {
+	return func(config *injectConfig) {
+		config.unresolvedTokenPolicy = policy
+	}
+}
+
+// WithUnresolvedTokenHook calls the hook for every token that can't be resolved, regardless of the
+// policy. This can be used to log broken links in production.
+func WithUnresolvedTokenHook(hook func(err *UnresolvedTokenError)) InjectOption {
+	return func(config *injectConfig) {
+		config.unresolvedTokenHook = hook
+	}
+}
@@ -0,0 +1,36 @@
+package gohateoas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnresolvedTokenError_MatchesSentinel(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	var err error = &UnresolvedTokenError{Type: "gohateoas.cupcake", Action: "self", Href: "/cupcakes/{ID}", Token: "ID"}
+
+	// Act
+	result := errors.Is(err, ErrUnresolvedToken)
+
+	// Assert
+	assert.True(t, result)
+	assert.EqualError(t, err, `unresolved token: {ID} in self link "/cupcakes/{ID}" of gohateoas.cupcake`)
+}
+
+func TestEncodingError_UnwrapsOriginalError(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	original := errors.New("oh no")
+
+	var err error = &EncodingError{Err: original}
+
+	// Act
+	result := errors.Is(err, original)
+
+	// Assert
+	assert.True(t, result)
+	assert.EqualError(t, err, "failed to encode object: oh no")
+}
@@ -1,19 +1,15 @@
 package gohateoas
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
-
-	"github.com/survivorbat/go-tsyncmap"
 )
 
-// typeCacheMap is used to easily fetch json keys from a type
-var typeCacheMap = &tsyncmap.Map[string, map[string]string]{}
-
 // iKind is an abstraction of reflect.Value and reflect.Type that allows us to make ensureConcrete generic.
 type iKind[T any] interface {
 	Kind() reflect.Kind
@@ -31,90 +27,219 @@ func ensureConcrete[T iKind[T]](value T) T {
 	return value
 }
 
-// errNotAStruct can be exported in the future if need be
-var errNotAStruct = errors.New("object is not a struct")
-
-// getFieldNameFromJson returns the field name from the json tag
-func getFieldNameFromJson(object any, jsonKey string) (string, error) {
+// getFieldNameFromJson returns the field name from the json tag. Only fields of the struct itself that have
+// a json name in their tag are returned, untagged and promoted fields are not walked through.
+func (l *LinkRegistry) getFieldNameFromJson(object any, jsonKey string) (string, error) {
 	typeInfo := ensureConcrete(reflect.TypeOf(object))
 	if typeInfo.Kind() != reflect.Struct {
-		return "", errNotAStruct
+		return "", ErrNotAStruct
+	}
+
+	field, ok := typeInfo.FieldByName(l.fieldsOf(typeInfo)[jsonKey])
+	if !ok || len(field.Index) != 1 || strings.Split(field.Tag.Get("json"), ",")[0] == "" {
+		return "", nil
 	}
 
-	typeName := typeNameOf(object)
+	return field.Name, nil
+}
 
-	// Check for cached values, this way we don't need to perform reflection
-	// every time we want to get the field name from a json key.
-	if cachedValue, ok := typeCacheMap.Load(typeName); ok {
-		return cachedValue[jsonKey], nil
+// fieldsOf returns the json fields of the struct type like jsonFieldsOf, these are cached per registry
+// so we don't need to perform reflection every time we want to get the field name from a json key.
+func (l *LinkRegistry) fieldsOf(typeInfo reflect.Type) map[string]string {
+	if cachedValue, ok := l.fields.Load(typeInfo); ok {
+		return cachedValue
 	}
 
-	// It does not
-	typeCache := map[string]string{}
+	fields := jsonFieldsOf(typeInfo)
+
+	l.fields.Store(typeInfo, fields)
+
+	return fields
+}
+
+// ClearFieldCache removes the cached json fields of all types, they are determined again when needed.
+// This is mostly useful in tests.
+func (l *LinkRegistry) ClearFieldCache() {
+	l.fields.Range(func(typeInfo reflect.Type, _ map[string]string) bool {
+		l.fields.Delete(typeInfo)
+
+		return true
+	})
+}
+
+// jsonFieldsOf returns a map of json keys to field names of the struct type. Like encoding/json, fields
+// without a json name use their field name and fields of embedded structs are promoted.
+func jsonFieldsOf(typeInfo reflect.Type) map[string]string {
+	fields := map[string]string{}
+	promoted := map[string]string{}
 
 	for i := 0; i < typeInfo.NumField(); i++ {
+		field := typeInfo.Field(i)
+
 		// Get the json tags of this field
-		jsonKey := typeInfo.Field(i).Tag.Get("json")
+		jsonTag := field.Tag.Get("json")
+
+		// Ignore fields that are explicitly left out
+		if jsonTag == "-" {
+			continue
+		}
+
+		// Take the first item from the list and use that as the json key
+		jsonKey := strings.Split(jsonTag, ",")[0]
+
+		// Fields of embedded structs without a json name end up in the parent
+		if embeddedType := ensureConcrete(field.Type); field.Anonymous && jsonKey == "" && embeddedType.Kind() == reflect.Struct {
+			for embeddedKey, embeddedField := range jsonFieldsOf(embeddedType) {
+				promoted[embeddedKey] = embeddedField
+			}
 
-		// Ignore empty json fields
-		if jsonKey == "" || jsonKey == "-" {
 			continue
 		}
 
-		// Take the first item from the list and use that as the json key, save it
-		// in the cache map.
-		jsonProperty := strings.Split(jsonKey, ",")[0]
-		typeCache[jsonProperty] = typeInfo.Field(i).Name
+		if !field.IsExported() {
+			continue
+		}
+
+		if jsonKey == "" {
+			jsonKey = field.Name
+		}
+
+		fields[jsonKey] = field.Name
 	}
 
-	typeCacheMap.Store(typeName, typeCache)
+	// Fields of the struct itself take precedence over promoted fields
+	for jsonKey, fieldName := range promoted {
+		if _, ok := fields[jsonKey]; !ok {
+			fields[jsonKey] = fieldName
+		}
+	}
 
-	return typeCache[jsonKey], nil
+	return fields
 }
 
-// tokenReplaceRegex is a regex that matches tokens in the form of {token}
+// tokenReplaceRegex is a regex that matches RFC 6570 expressions in the form of {token} or {?token,other}
 var tokenReplaceRegex = regexp.MustCompile(`{([^{}]*)}`)
 
-// injectLinks injects the actual links into the struct if any are registered
-func injectLinks(registry LinkRegistry, object any, result map[string]any) {
-	// Add links if there are any
-	links := registry[typeNameOf(object)]
+// resolveLinks returns the links registered on the object, with the URI templates in their hrefs
+// expanded using the values of the corresponding json fields in the result.
+func resolveLinks(config *injectConfig, object any, result map[string]any) map[string]LinkInfo {
+	links := config.links[typeKeyOf(object)]
 
 	if len(links) == 0 {
-		return
+		return nil
 	}
 
-	linkMap := map[string]LinkInfo{}
+	linkMap := make(map[string]LinkInfo, len(links))
+
+	lookup := func(name string) (any, bool) {
+		if strings.HasPrefix(name, variablePrefix) {
+			if value, ok := lookupPath(config.variables, strings.TrimPrefix(name, variablePrefix)); ok {
+				return value, true
+			}
+		}
+
+		if value, ok := lookupPath(result, name); ok {
+			return value, true
+		}
+
+		return config.lookupScope(name)
+	}
 
-	// Loop through every link and inject it into the object, replacing tokens
-	// with the appropriate values.
+	// Loop through every link and expand its template with the appropriate values.
 	for linkType, linkInfo := range links {
-		// Find matches for tokens in the linkInfo like {id} or {name}
-		matches := tokenReplaceRegex.FindAllStringSubmatch(linkInfo.Href, -1)
+		if linkInfo.condition != nil && !linkInfo.condition(object) {
+			continue
+		}
+
+		linkInfo, ok := resolveLink(config, object, linkType, linkInfo, lookup)
+		if !ok {
+			continue
+		}
 
-		for _, match := range matches {
-			// Check if the value is in the object, like "id" or "name"
-			urlValue, ok := result[match[1]]
+		linkInfo.Href = absoluteHref(config.baseURL, linkInfo.Href)
 
+		linkMap[linkType] = linkInfo
+	}
+
+	// The self link identifies the object when decisions of the authorizer are cached
+	self := linkMap["self"].Href
+
+	for linkType, linkInfo := range linkMap {
+		if !config.authorize(object, self, linkType, linkInfo) {
+			delete(linkMap, linkType)
+		}
+	}
+
+	return linkMap
+}
+
+// resolveLink computes the link or expands its template, it returns false if the link should be left out.
+func resolveLink(config *injectConfig, object any, action string, linkInfo LinkInfo, lookup func(name string) (any, bool)) (LinkInfo, bool) {
+	if linkInfo.compute != nil {
+		computed, ok := linkInfo.compute(object)
+
+		// Options like WithRequestBody may have been applied to the registered link
+		if computed.RequestBody == nil {
+			computed.RequestBody = linkInfo.RequestBody
+		}
+
+		return computed, ok
+	}
+
+	template := linkInfo.Href
+
+	var unresolved []string
+	linkInfo.Href, unresolved, linkInfo.Templated = expandTemplate(template, lookup, config.preserveEncodedValues)
+
+	for _, token := range unresolved {
+		config.reportUnresolved(object, action, template, token)
+	}
+
+	if len(unresolved) > 0 && config.unresolvedTokenPolicy == DropUnresolvedLinks {
+		return linkInfo, false
+	}
+
+	return linkInfo, true
+}
+
+// lookupPath returns the value at the dotted json path in the result, like bakery.id or owners.0.id.
+// Keys that contain dots themselves take precedence over paths.
+func lookupPath(result map[string]any, path string) (any, bool) {
+	if value, ok := result[path]; ok {
+		return value, true
+	}
+
+	var current any = result
+
+	for _, segment := range strings.Split(path, ".") {
+		switch typedValue := current.(type) {
+		case map[string]any:
+			value, ok := typedValue[segment]
 			if !ok {
-				continue
+				return nil, false
 			}
 
-			// Replace the {name} with the actual value
-			matchString := fmt.Sprintf("{%s}", match[1])
-			linkInfo.Href = strings.ReplaceAll(linkInfo.Href, matchString, fmt.Sprintf("%v", urlValue))
-		}
+			current = value
 
-		// Save the linkInfo in the object
-		linkMap[linkType] = linkInfo
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(typedValue) {
+				return nil, false
+			}
+
+			current = typedValue[index]
+
+		default:
+			return nil, false
+		}
 	}
 
-	// Add the _links property
-	result["_links"] = linkMap
+	return current, true
 }
 
-// walkThroughObject goes through the object and injects links into the structs it comes across
-func walkThroughObject(registry LinkRegistry, object any, result any) {
+// walkThroughObject goes through the object and injects links into the structs it comes across,
+// links are resolved before descending into an object and rendered after its children have been rendered.
+func walkThroughObject(config *injectConfig, object any, result any) {
 	// Prevent nil pointer dereference
 	if result == nil {
 		return
@@ -127,18 +252,36 @@ func walkThroughObject(registry LinkRegistry, object any, result any) {
 	case []any:
 		// Loop through the slice's entries and recursively walk through those objects
 		for index := range result {
-			walkThroughObject(registry, ensureConcrete(reflectValue.Index(index)).Interface(), result[index])
+			// Nil pointers in the slice don't have anything to walk through
+			entry := ensureConcrete(reflectValue.Index(index))
+			if !entry.IsValid() {
+				continue
+			}
+
+			walkThroughObject(config, entry.Interface(), result[index])
 		}
 
 	case map[string]any:
-		// Actually inject links, since this is a struct
-		injectLinks(registry, object, result)
+		_, registered := config.links[typeKeyOf(object)]
+
+		current := &Resource{
+			Object:     object,
+			Result:     result,
+			Links:      resolveLinks(config, object, result),
+			Registered: registered,
+		}
+
+		// Nested objects may refer to the values of registered objects they're in
+		if registered {
+			config.scopes = append(config.scopes, scope{name: shortTypeNameOf(object), result: result})
+			defer func() { config.scopes = config.scopes[:len(config.scopes)-1] }()
+		}
 
 		// Loop through the map's entries and recursively walk through those objects
 		for jsonKey, value := range result {
 			switch resultCastValue := value.(type) {
 			case map[string]any, []any:
-				fieldName, err := getFieldNameFromJson(object, jsonKey)
+				fieldName, err := config.registry.getFieldNameFromJson(object, jsonKey)
 				if err != nil {
 					continue
 				}
@@ -148,36 +291,228 @@ func walkThroughObject(registry LinkRegistry, object any, result any) {
 					continue
 				}
 
-				walkThroughObject(registry, fieldValue.Interface(), resultCastValue)
+				// Keep track of nested objects that have links registered, some renderers treat them differently
+				if _, ok := config.links[typeKeyOf(fieldValue.Interface())]; ok {
+					current.Embedded = append(current.Embedded, jsonKey)
+				}
+
+				walkThroughObject(config, fieldValue.Interface(), resultCastValue)
 			}
 		}
+
+		// Map iteration is random, but we want the output to be predictable
+		sort.Strings(current.Embedded)
+
+		// Actually inject links, since this is a struct
+		config.renderer.RenderLinks(current)
+	}
+}
+
+// InjectOption is used to configure the output of InjectLinksWith.
+type InjectOption func(*injectConfig)
+
+// injectConfig contains the settings and state of a single InjectLinksWith call
+type injectConfig struct {
+	registry *LinkRegistry
+
+	// links is a snapshot of the registry, so registrations during the call don't affect the output
+	links    map[reflect.Type]map[string]LinkInfo
+	renderer LinkRenderer
+
+	//nolint:containedctx // The config only lives as long as a single call
+	ctx        context.Context
+	authorizer *authorizerHandle
+
+	unresolvedTokenPolicy UnresolvedTokenPolicy
+	unresolvedTokenHook   func(err *UnresolvedTokenError)
+
+	preserveEncodedValues bool
+	baseURL               string
+
+	// variables are request-scoped values that can be referenced as {ctx.name}
+	variables map[string]any
+
+	// scopes contains the registered objects that enclose the object that is currently walked through
+	scopes []scope
+
+	// err is the first error that occurred while walking through the object
+	err error
+}
+
+// newInjectConfig returns the config with defaults, overridden by the given options
+func newInjectConfig(registry *LinkRegistry, options ...InjectOption) *injectConfig {
+	config := &injectConfig{ctx: context.Background(), registry: registry, links: registry.snapshot(), renderer: PropertyRenderer{}}
+
+	if registry != nil {
+		config.baseURL = registry.baseURL
+	}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	return config
+}
+
+// scope is a registered object that nested objects can refer to in their links
+type scope struct {
+	name   string
+	result map[string]any
+}
+
+// parentScope refers to the nearest enclosing registered object in a template variable, like $parent.id
+const parentScope = "$parent"
+
+// lookupScope resolves a variable like $parent.id, $parent.$parent.id or bakery.id against the registered
+// objects that enclose the current object. Named scopes match the type name of the nearest enclosing object
+// of that type, ignoring case.
+func (c *injectConfig) lookupScope(name string) (any, bool) {
+	scopeName, path, ok := strings.Cut(name, ".")
+	if !ok {
+		return nil, false
+	}
+
+	index := -1
+
+	switch scopeName {
+	case parentScope, "parent":
+		index = len(c.scopes) - 1
+
+		for strings.HasPrefix(path, parentScope+".") {
+			path = strings.TrimPrefix(path, parentScope+".")
+			index--
+		}
+
+	default:
+		for i := len(c.scopes) - 1; i >= 0; i-- {
+			if strings.EqualFold(c.scopes[i].name, scopeName) {
+				index = i
+
+				break
+			}
+		}
+	}
+
+	if index < 0 {
+		return nil, false
+	}
+
+	return lookupPath(c.scopes[index].result, path)
+}
+
+// reportUnresolved passes an UnresolvedTokenError to the hook and saves it if the policy
+// is FailOnUnresolvedTokens, only the first error is kept.
+func (c *injectConfig) reportUnresolved(object any, action string, href string, token string) {
+	err := &UnresolvedTokenError{Type: typeNameOf(object), Action: action, Href: href, Token: token}
+
+	if c.unresolvedTokenHook != nil {
+		c.unresolvedTokenHook(err)
+	}
+
+	if c.unresolvedTokenPolicy == FailOnUnresolvedTokens && c.err == nil {
+		c.err = err
 	}
 }
 
 // InjectLinks is similar to json.Marshal, but it will inject links into the response if the
 // registry has any links for the given type. It does this recursively.
-func InjectLinks(registry LinkRegistry, object any) []byte {
-	rawResponseJson, _ := json.Marshal(object)
+func InjectLinks(registry *LinkRegistry, object any) []byte {
+	return InjectLinksWith(registry, object)
+}
+
+// InjectLinksWith is similar to InjectLinks, but allows you to change the output format using
+// options like AsHAL or WithRenderer.
+func InjectLinksWith(registry *LinkRegistry, object any, options ...InjectOption) []byte {
+	result, _ := marshalWithLinks(object, newInjectConfig(registry, options...))
+
+	return result
+}
+
+// InjectLinksContext is similar to InjectLinksWith, but makes the variables in the context available to
+// templates as {ctx.name}. Use ContextWithVariable to add variables like a tenant id to the context. The
+// context is also passed to the Authorizer, if one is set using WithAuthorizer.
+func InjectLinksContext(ctx context.Context, registry *LinkRegistry, object any, options ...InjectOption) []byte {
+	options = append([]InjectOption{withContext(ctx)}, options...)
+
+	return InjectLinksWith(registry, object, options...)
+}
+
+// withContext sets the context of the call and makes its variables available to templates
+func withContext(ctx context.Context) InjectOption {
+	withVariables := WithVariables(VariablesFromContext(ctx))
 
-	// If the registry is empty, don't bother doing any reflection
-	if len(registry) == 0 {
-		return rawResponseJson
+	return func(config *injectConfig) {
+		config.ctx = ctx
+		withVariables(config)
 	}
+}
 
-	var resultObject any
+// MarshalWithLinks is similar to InjectLinksWith, but returns an error if the object can not be
+// encoded or if a token in a href can not be resolved. Errors are of the type EncodingError or
+// UnresolvedTokenError. Use OnUnresolvedToken to allow unresolved tokens.
+func MarshalWithLinks(registry *LinkRegistry, object any, options ...InjectOption) ([]byte, error) {
+	options = append([]InjectOption{OnUnresolvedToken(FailOnUnresolvedTokens)}, options...)
+
+	return marshalWithLinks(object, newInjectConfig(registry, options...))
+}
+
+// MarshalWithLinksContext is similar to MarshalWithLinks, but makes the variables in the context available
+// to templates as {ctx.name} and passes it to the Authorizer, like InjectLinksContext.
+func MarshalWithLinksContext(ctx context.Context, registry *LinkRegistry, object any, options ...InjectOption) ([]byte, error) {
+	options = append([]InjectOption{withContext(ctx)}, options...)
+
+	return MarshalWithLinks(registry, object, options...)
+}
+
+// marshalWithLinks encodes the object to json and injects links using the given config
+func marshalWithLinks(object any, config *injectConfig) ([]byte, error) {
+	rawResponseJson, err := json.Marshal(object)
+	if err != nil {
+		return nil, &EncodingError{Err: err}
+	}
+
+	documentRenderer, isDocument := config.renderer.(DocumentRenderer)
+
+	walk := len(config.links) > 0
 
 	//nolint:exhaustive // Doesn't make sense to add more here
 	switch ensureConcrete(reflect.ValueOf(object)).Kind() {
 	case reflect.Slice, reflect.Struct, reflect.Array:
-		_ = json.Unmarshal(rawResponseJson, &resultObject)
-		walkThroughObject(registry, object, resultObject)
+		// If the registry is empty, don't bother doing any reflection
+		if !walk && !isDocument {
+			return rawResponseJson, nil
+		}
 
 	default:
-		// Prevent unnecessary json.Marshal
-		return rawResponseJson
+		// Prevent unnecessary json.Marshal, unless the renderer has to wrap the document
+		if !isDocument {
+			return rawResponseJson, nil
+		}
+
+		walk = false
 	}
 
-	finalResponse, _ := json.Marshal(resultObject)
+	var resultObject any
+	if err := json.Unmarshal(rawResponseJson, &resultObject); err != nil {
+		return nil, &EncodingError{Err: err}
+	}
+
+	if walk {
+		walkThroughObject(config, object, resultObject)
+
+		if config.err != nil {
+			return nil, config.err
+		}
+	}
+
+	if isDocument {
+		resultObject = documentRenderer.RenderDocument(resultObject)
+	}
+
+	finalResponse, err := json.Marshal(resultObject)
+	if err != nil {
+		return nil, &EncodingError{Err: err}
+	}
 
-	return finalResponse
+	return finalResponse, nil
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 
@@ -609,6 +610,407 @@ func TestInjectLinks_IgnoresIfRegistryIsEmpty(t *testing.T) {
 	assert.Equal(t, normalJson, result)
 }
 
+func TestInjectLinksWith_AlwaysRendersDocument(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		registry *LinkRegistry
+		input    any
+		option   InjectOption
+		expected string
+	}{
+		"json:api with empty registry": {
+			registry: NewLinkRegistry(),
+			input:    bakery{ID: 1},
+			option:   AsJSONAPI(),
+			expected: `{"data":{"id":1}}`,
+		},
+		"json:api with string": {
+			registry: func() *LinkRegistry {
+				registry := NewLinkRegistry()
+				RegisterOn(registry, bakery{}, Self("/bakeries/{id}", ""))
+
+				return registry
+			}(),
+			input:    "test",
+			option:   AsJSONAPI(),
+			expected: `{"data":"test"}`,
+		},
+		"collection+json with empty registry": {
+			registry: NewLinkRegistry(),
+			input:    []bakery{{ID: 1}},
+			option:   AsCollectionJSON(),
+			expected: `{"collection":{"version":"1.0","items":[{"id":1}]}}`,
+		},
+		"hydra with empty registry": {
+			registry: NewLinkRegistry(),
+			input:    []bakery{{ID: 1}},
+			option:   AsHydra(),
+			expected: `{"@context":{"hydra":"http://www.w3.org/ns/hydra/core#"},"@type":"hydra:Collection","hydra:member":[{"id":1}],"hydra:totalItems":1}`,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Act
+			result := InjectLinksWith(testData.registry, testData.input, testData.option)
+
+			// Assert
+			assert.JSONEq(t, testData.expected, string(result))
+		})
+	}
+}
+
+func TestInjectLinks_ExpandsURITemplates(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	type testType4 struct {
+		ID   int      `json:"id"`
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+
+	registry := NewLinkRegistry()
+	RegisterOn(registry, testType4{},
+		Self("/api/v1/cakes/{id}", ""),
+		Custom("tagged", LinkInfo{Method: http.MethodGet, Href: "/api/v1/cakes{?tags*}"}),
+		Custom("search", LinkInfo{Method: http.MethodGet, Href: "/api/v1/cakes/{name}{?page,size}"}))
+
+	object := testType4{ID: 12345678, Name: "a b/c", Tags: []string{"sweet", "big"}}
+
+	// Act
+	result := InjectLinks(registry, object)
+
+	// Assert
+	expected := `{"_links":{` +
+		`"search":{"method":"GET","href":"/api/v1/cakes/a%20b%2Fc{?page,size}","comment":"","templated":true},` +
+		`"self":{"method":"GET","href":"/api/v1/cakes/12345678","comment":""},` +
+		`"tagged":{"method":"GET","href":"/api/v1/cakes?tags=sweet\u0026tags=big","comment":""}},` +
+		`"id":12345678,"name":"a b/c","tags":["sweet","big"]}`
+
+	assert.Equal(t, expected, string(result))
+}
+
+func TestInjectLinksWith_EncodesValues(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		name     string
+		options  []InjectOption
+		expected map[string]any
+	}{
+		"plain": {
+			name: "red velvet",
+			expected: map[string]any{
+				"path":     "/api/v1/cupcakes/red%20velvet",
+				"query":    "/api/v1/cupcakes?name=red%20velvet",
+				"template": "/api/v1/cupcakes?name=red%20velvet",
+			},
+		},
+		"slash and question mark": {
+			name: "red velvet/vegan?",
+			expected: map[string]any{
+				"path":     "/api/v1/cupcakes/red%20velvet%2Fvegan%3F",
+				"query":    "/api/v1/cupcakes?name=red%20velvet%2Fvegan%3F",
+				"template": "/api/v1/cupcakes?name=red%20velvet%2Fvegan%3F",
+			},
+		},
+		"path traversal": {
+			name: "../../admin",
+			expected: map[string]any{
+				"path":     "/api/v1/cupcakes/..%2F..%2Fadmin",
+				"query":    "/api/v1/cupcakes?name=..%2F..%2Fadmin",
+				"template": "/api/v1/cupcakes?name=..%2F..%2Fadmin",
+			},
+		},
+		"hash and ampersand": {
+			name: "a#b&c=d",
+			expected: map[string]any{
+				"path":     "/api/v1/cupcakes/a%23b%26c%3Dd",
+				"query":    "/api/v1/cupcakes?name=a%23b%26c%3Dd",
+				"template": "/api/v1/cupcakes?name=a%23b%26c%3Dd",
+			},
+		},
+		"percent": {
+			name: "100%25 sugar",
+			expected: map[string]any{
+				"path":     "/api/v1/cupcakes/100%2525%20sugar",
+				"query":    "/api/v1/cupcakes?name=100%2525%20sugar",
+				"template": "/api/v1/cupcakes?name=100%2525%20sugar",
+			},
+		},
+		"unicode": {
+			name: "crème brûlée",
+			expected: map[string]any{
+				"path":     "/api/v1/cupcakes/cr%C3%A8me%20br%C3%BBl%C3%A9e",
+				"query":    "/api/v1/cupcakes?name=cr%C3%A8me%20br%C3%BBl%C3%A9e",
+				"template": "/api/v1/cupcakes?name=cr%C3%A8me%20br%C3%BBl%C3%A9e",
+			},
+		},
+		"already encoded": {
+			name:    "100%25 sugar/%zz",
+			options: []InjectOption{PreserveEncodedValues()},
+			expected: map[string]any{
+				"path":     "/api/v1/cupcakes/100%25%20sugar%2F%25zz",
+				"query":    "/api/v1/cupcakes?name=100%25%20sugar%2F%25zz",
+				"template": "/api/v1/cupcakes?name=100%25%20sugar%2F%25zz",
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+			RegisterOn(registry, cupcake{},
+				Custom("path", LinkInfo{Href: "/api/v1/cupcakes/{name}"}),
+				Custom("query", LinkInfo{Href: "/api/v1/cupcakes?name={name}"}),
+				Custom("template", LinkInfo{Href: "/api/v1/cupcakes{?name}"}))
+
+			// Act
+			result := InjectLinksWith(registry, cupcake{Name: testData.name}, testData.options...)
+
+			// Assert
+			var decoded struct {
+				Links map[string]LinkInfo `json:"_links"`
+			}
+
+			_ = json.Unmarshal(result, &decoded)
+
+			hrefs := map[string]any{}
+			for action, linkInfo := range decoded.Links {
+				hrefs[action] = linkInfo.Href
+			}
+
+			assert.Equal(t, testData.expected, hrefs)
+		})
+	}
+}
+
+func TestInjectLinks_ResolvesDottedPaths(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		object   any
+		expected map[string]LinkInfo
+	}{
+		"nested object": {
+			object: &cupcake{ID: 3, Bakery: &bakery{ID: 5}},
+			expected: map[string]LinkInfo{
+				"self": {Method: http.MethodGet, Href: "/bakeries/5/cupcakes/3"},
+			},
+		},
+		"nil nested object": {
+			object: &cupcake{ID: 3},
+			expected: map[string]LinkInfo{
+				"self": {Method: http.MethodGet, Href: "/bakeries/{bakery.id}/cupcakes/3", Templated: true},
+			},
+		},
+		"array index": {
+			object: &bakery{ID: 5, Cupcakes: []*cupcake{{ID: 7}, {ID: 8}}},
+			expected: map[string]LinkInfo{
+				"first": {Method: http.MethodGet, Href: "/cupcakes/7"},
+				"last":  {Method: http.MethodGet, Href: "/cupcakes/{cupcakes.2.id}", Templated: true},
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+			RegisterOn(registry, cupcake{}, Self("/bakeries/{bakery.id}/cupcakes/{id}", ""))
+			RegisterOn(registry, bakery{},
+				Custom("first", LinkInfo{Method: http.MethodGet, Href: "/cupcakes/{cupcakes.0.id}"}),
+				Custom("last", LinkInfo{Method: http.MethodGet, Href: "/cupcakes/{cupcakes.2.id}"}))
+
+			// Act
+			result := InjectLinks(registry, testData.object)
+
+			// Assert
+			var decoded struct {
+				Links map[string]LinkInfo `json:"_links"`
+			}
+
+			_ = json.Unmarshal(result, &decoded)
+
+			assert.Equal(t, testData.expected, decoded.Links)
+		})
+	}
+}
+
+func TestInjectLinks_ResolvesParentScopes(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		href     string
+		expected []string
+	}{
+		"parent": {
+			href:     "/bakeries/{$parent.id}/cupcakes/{id}",
+			expected: []string{"/bakeries/2/cupcakes/3", "/bakeries/2/cupcakes/4"},
+		},
+		"parent without dollar": {
+			href:     "/bakeries/{parent.id}/cupcakes/{id}",
+			expected: []string{"/bakeries/2/cupcakes/3", "/bakeries/2/cupcakes/4"},
+		},
+		"named scope": {
+			href:     "/bakeries/{bakery.id}/cupcakes/{id}",
+			expected: []string{"/bakeries/2/cupcakes/3", "/bakeries/2/cupcakes/4"},
+		},
+		"grandparent": {
+			href:     "/cupcakes/{$parent.$parent.id}/related/{id}",
+			expected: []string{"/cupcakes/1/related/3", "/cupcakes/1/related/4"},
+		},
+		"named scope of grandparent": {
+			href:     "/cupcakes/{cupcake.id}/related/{id}",
+			expected: []string{"/cupcakes/1/related/3", "/cupcakes/1/related/4"},
+		},
+		"too far up": {
+			href:     "/things/{$parent.$parent.$parent.id}",
+			expected: []string{"/things/{$parent.$parent.$parent.id}", "/things/{$parent.$parent.$parent.id}"},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+			RegisterOn(registry, cupcake{}, Self(testData.href, ""))
+			RegisterOn(registry, bakery{}, Self("/bakeries/{id}", ""))
+
+			object := &cupcake{ID: 1, Bakery: &bakery{ID: 2, Cupcakes: []*cupcake{{ID: 3}, {ID: 4}}}}
+
+			// Act
+			result := InjectLinks(registry, object)
+
+			// Assert
+			var decoded struct {
+				Bakery struct {
+					Cupcakes []struct {
+						Links map[string]LinkInfo `json:"_links"`
+					} `json:"cupcakes"`
+				} `json:"bakery"`
+			}
+
+			_ = json.Unmarshal(result, &decoded)
+
+			hrefs := make([]string, 0, len(decoded.Bakery.Cupcakes))
+			for _, nested := range decoded.Bakery.Cupcakes {
+				hrefs = append(hrefs, nested.Links["self"].Href)
+			}
+
+			assert.Equal(t, testData.expected, hrefs)
+		})
+	}
+}
+
+type conditionalOrder struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+	Paid   bool   `json:"paid"`
+}
+
+func TestInjectLinks_AppliesConditions(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		object   any
+		expected []string
+	}{
+		"draft": {
+			object:   conditionalOrder{ID: 1, Status: "draft"},
+			expected: []string{"delete", "publish", "self"},
+		},
+		"pointer to draft": {
+			object:   &conditionalOrder{ID: 1, Status: "draft"},
+			expected: []string{"delete", "publish", "self"},
+		},
+		"paid draft": {
+			object:   conditionalOrder{ID: 1, Status: "draft", Paid: true},
+			expected: []string{"delete", "publish", "refund", "self"},
+		},
+		"shipped": {
+			object:   conditionalOrder{ID: 1, Status: "shipped", Paid: true},
+			expected: []string{"self"},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			isDraft := func(order conditionalOrder) bool { return order.Status == "draft" }
+			isPaid := func(order *conditionalOrder) bool { return order.Paid }
+
+			registry := NewLinkRegistry()
+			RegisterOn(registry, conditionalOrder{},
+				Self("/orders/{id}", ""),
+				When(isDraft,
+					Delete("/orders/{id}", ""),
+					Custom("publish", LinkInfo{Method: http.MethodPost, Href: "/orders/{id}/publish"}),
+					When(isPaid, Custom("refund", LinkInfo{Method: http.MethodPost, Href: "/orders/{id}/refund"}))))
+
+			// Act
+			result := InjectLinks(registry, testData.object)
+
+			// Assert
+			var decoded struct {
+				Links map[string]LinkInfo `json:"_links"`
+			}
+
+			_ = json.Unmarshal(result, &decoded)
+
+			actions := make([]string, 0, len(decoded.Links))
+			for action := range decoded.Links {
+				actions = append(actions, action)
+			}
+
+			sort.Strings(actions)
+
+			assert.Equal(t, testData.expected, actions)
+		})
+	}
+}
+
+func TestInjectLinks_AppliesConditionsToSliceEntries(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, conditionalOrder{},
+		When(func(order conditionalOrder) bool { return order.Status == "draft" },
+			Delete("/orders/{id}", "")))
+
+	object := []*conditionalOrder{{ID: 1, Status: "draft"}, {ID: 2, Status: "shipped"}}
+
+	// Act
+	result := InjectLinks(registry, object)
+
+	// Assert
+	expected := `[
+		{"id":1,"status":"draft","paid":false,"_links":{"delete":{"method":"DELETE","href":"/orders/1","comment":""}}},
+		{"id":2,"status":"shipped","paid":false}
+	]`
+	assert.JSONEq(t, expected, string(result))
+}
+
+func TestWhen_IgnoresObjectsOfOtherTypes(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, cupcake{}, When(func(string) bool { return true }, Self("/cupcakes/{id}", "")))
+
+	// Act
+	result := InjectLinks(registry, cupcake{ID: 1})
+
+	// Assert
+	assert.JSONEq(t, `{"id":1,"name":"","bakery":null}`, string(result))
+}
+
 func TestGetFieldNameFromJson_ReturnsExpectedName(t *testing.T) {
 	t.Parallel()
 
@@ -636,7 +1038,7 @@ func TestGetFieldNameFromJson_ReturnsExpectedName(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 			// Act
-			result, err := getFieldNameFromJson(testType3{}, testData.jsonKey)
+			result, err := NewLinkRegistry().getFieldNameFromJson(testType3{}, testData.jsonKey)
 
 			// Assert
 			assert.NoError(t, err)
@@ -651,13 +1053,60 @@ func TestGetFieldNameFromJson_ReturnsEmptyStringOnNonStructType(t *testing.T) {
 	type testType4s []string
 
 	// Act
-	result, err := getFieldNameFromJson(testType4s{}, "any")
+	result, err := NewLinkRegistry().getFieldNameFromJson(testType4s{}, "any")
 
 	// Assert
 	assert.EqualError(t, err, "object is not a struct")
 	assert.Equal(t, "", result)
 }
 
+func TestMarshalWithLinks_SkipsNilSliceEntries(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, cheese{}, Self("/cheeses/{id}", ""))
+
+	input := []*cheese{nil, {ID: 1}}
+
+	// Act
+	result, err := MarshalWithLinks(registry, input)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[null,{"id":1,"_links":{"self":{"method":"GET","href":"/cheeses/1","comment":""}}}]`, string(result))
+}
+
+func TestInjectLinks_IgnoresUntaggedAndPromotedFields(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	type embeddedCheese struct {
+		Cheese *cheese `json:"cheese"`
+	}
+
+	type cheeseBoard struct {
+		embeddedCheese
+
+		Untagged *cheese
+		Tagged   *cheese `json:"tagged"`
+	}
+
+	registry := NewLinkRegistry()
+	RegisterOn(registry, cheese{}, Self("/cheeses/{id}", ""))
+
+	input := cheeseBoard{embeddedCheese: embeddedCheese{Cheese: &cheese{ID: 1}}, Untagged: &cheese{ID: 2}, Tagged: &cheese{ID: 3}}
+
+	// Act
+	result := InjectLinks(registry, input)
+
+	// Assert
+	expected := `{
+		"cheese":{"id":1},
+		"Untagged":{"id":2},
+		"tagged":{"id":3,"_links":{"self":{"method":"GET","href":"/cheeses/3","comment":""}}}
+	}`
+	assert.JSONEq(t, expected, string(result))
+}
+
 func TestGetFieldNameFromJson_IgnoresMissingJsonFields(t *testing.T) {
 	t.Parallel()
 
@@ -683,7 +1132,7 @@ func TestGetFieldNameFromJson_IgnoresMissingJsonFields(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 			// Act
-			result, err := getFieldNameFromJson(OtherType1{}, testData.jsonKey)
+			result, err := NewLinkRegistry().getFieldNameFromJson(OtherType1{}, testData.jsonKey)
 
 			// Assert
 			assert.NoError(t, err)
@@ -848,18 +1297,20 @@ func BenchmarkInjectLinks(b *testing.B) {
 		},
 	}
 
-	registryTests := map[string]func() LinkRegistry{
+	registryTests := map[string]func() *LinkRegistry{
 		// This test won't do much because there's an if-statement blocking execution, but it gives us a bit of insight
-		"no links": NewLinkRegistry,
+		"no links": func() *LinkRegistry {
+			return NewLinkRegistry()
+		},
 
-		"3 links for fridge": func() LinkRegistry {
+		"3 links for fridge": func() *LinkRegistry {
 			registry := NewLinkRegistry()
 			RegisterOn(registry, fridge{}, Self("/api/fridges", "Get this fridge"), Post("/api/fridges", "Create a new fridge"), Delete("/api/v1/fridges/{id}", "Delete a fridge"))
 
 			return registry
 		},
 
-		"3 links for all objects": func() LinkRegistry {
+		"3 links for all objects": func() *LinkRegistry {
 			registry := NewLinkRegistry()
 			RegisterOn(registry, fridge{}, Self("/api/fridges", "Get this fridge"), Post("/api/fridges", "Create a new fridge"), Delete("/api/v1/fridges/{id}", "Delete a fridge"))
 			RegisterOn(registry, vegetable{}, Self("/api/vegetables", "Get this vegetable"), Post("/api/vegetables", "Create a new vegetable"), Delete("/api/v1/vegetables/{id}", "Delete a vegetable"))
@@ -880,3 +1331,230 @@ func BenchmarkInjectLinks(b *testing.B) {
 		}
 	}
 }
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) {
+	return nil, assert.AnError
+}
+
+func TestMarshalWithLinks_ReturnsEncodingErrors(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	type channelType struct {
+		Channel chan int `json:"channel"`
+	}
+
+	type failingType struct {
+		Failing failingMarshaler `json:"failing"`
+	}
+
+	registry := NewLinkRegistry()
+	RegisterOn(registry, channelType{}, Self("/channels", ""))
+	RegisterOn(registry, failingType{}, Self("/failing", ""))
+
+	tests := map[string]struct {
+		input    any
+		expected any
+	}{
+		"channel": {
+			input:    channelType{Channel: make(chan int)},
+			expected: new(*json.UnsupportedTypeError),
+		},
+		"failing marshaler": {
+			input:    &failingType{},
+			expected: new(*json.MarshalerError),
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Act
+			result, err := MarshalWithLinks(registry, testData.input)
+
+			// Assert
+			assert.Nil(t, result)
+
+			var encodingError *EncodingError
+			assert.ErrorAs(t, err, &encodingError)
+			assert.ErrorAs(t, err, testData.expected)
+		})
+	}
+}
+
+func TestMarshalWithLinks_ReturnsUnresolvedTokenError(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, &cupcake{}, Self("/api/v1/cupcakes/{ID}", "get itself"))
+
+	input := &bakery{ID: 5, Cupcake: &cupcake{ID: 3}}
+
+	// Act
+	result, err := MarshalWithLinks(registry, input)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrUnresolvedToken)
+
+	var tokenError *UnresolvedTokenError
+	if assert.ErrorAs(t, err, &tokenError) {
+		assert.Equal(t, &UnresolvedTokenError{Type: "gohateoas.cupcake", Action: "self", Href: "/api/v1/cupcakes/{ID}", Token: "ID"}, tokenError)
+	}
+}
+
+func TestMarshalWithLinks_AllowsOptionalExpressions(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, &bakery{}, Index("/api/v1/bakeries{?page,size}", ""))
+
+	var hookCalls int
+
+	// Act
+	result, err := MarshalWithLinks(registry, &bakery{ID: 5}, WithUnresolvedTokenHook(func(*UnresolvedTokenError) {
+		hookCalls++
+	}))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Zero(t, hookCalls)
+	assert.JSONEq(t, `{"id": 5, "_links": {"index": {"method": "GET", "href": "/api/v1/bakeries{?page,size}", "comment": "", "templated": true}}}`, string(result))
+}
+
+func TestMarshalWithLinks_FailsOnReservedAndFragmentExpressions(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		href string
+	}{
+		"reserved": {href: "{+pathh}/x"},
+		"fragment": {href: "/x{#sectoin}"},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+			RegisterOn(registry, &bakery{}, Self(testData.href, ""))
+
+			// Act
+			result, err := MarshalWithLinks(registry, &bakery{ID: 5})
+
+			// Assert
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, ErrUnresolvedToken)
+		})
+	}
+}
+
+func TestMarshalWithLinks_ReturnsSameResultAsInjectLinks(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, &cupcake{}, Self("/api/v1/cupcakes/{id}", "get itself"))
+	RegisterOn(registry, &bakery{}, Self("/api/v1/bakeries/{id}", "get a bakery by id"))
+
+	input := []*bakery{{ID: 5, Cupcakes: []*cupcake{{ID: 3}}}}
+
+	// Act
+	result, err := MarshalWithLinks(registry, input)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, InjectLinks(registry, input), result)
+}
+
+func TestInjectLinksWith_AppliesUnresolvedTokenPolicy(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		policy   UnresolvedTokenPolicy
+		expected []byte
+	}{
+		"keep": {
+			policy:   KeepUnresolvedTokens,
+			expected: []byte(`{"_links":{"other":{"method":"GET","href":"/api/v1/bakeries/5/{name}","comment":"","templated":true},"self":{"method":"GET","href":"/api/v1/bakeries/5","comment":""}},"id":5}`),
+		},
+		"drop": {
+			policy:   DropUnresolvedLinks,
+			expected: []byte(`{"_links":{"self":{"method":"GET","href":"/api/v1/bakeries/5","comment":""}},"id":5}`),
+		},
+		"fail": {
+			policy: FailOnUnresolvedTokens,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+			RegisterOn(registry, &bakery{},
+				Self("/api/v1/bakeries/{id}", ""),
+				Custom("other", LinkInfo{Method: http.MethodGet, Href: "/api/v1/bakeries/{id}/{name}"}))
+
+			var reported []*UnresolvedTokenError
+
+			// Act
+			result := InjectLinksWith(registry, &bakery{ID: 5}, OnUnresolvedToken(testData.policy), WithUnresolvedTokenHook(func(err *UnresolvedTokenError) {
+				reported = append(reported, err)
+			}))
+
+			// Assert
+			assert.Equal(t, testData.expected, result)
+			assert.Equal(t, []*UnresolvedTokenError{
+				{Type: "gohateoas.bakery", Action: "other", Href: "/api/v1/bakeries/{id}/{name}", Token: "name"},
+			}, reported)
+		})
+	}
+}
+
+func TestMarshalWithLinks_AllowsOverridingUnresolvedTokenPolicy(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, &bakery{}, Self("/api/v1/bakeries/{slug}", ""))
+
+	// Act
+	result, err := MarshalWithLinks(registry, &bakery{ID: 5}, OnUnresolvedToken(DropUnresolvedLinks))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id": 5}`, string(result))
+}
+
+func TestJsonFieldsOf_FollowsEncodingJsonRules(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	type inner struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Other string
+	}
+
+	type outer struct {
+		*inner
+
+		Name     string `json:"name"`
+		Untagged string
+		Ignored  string `json:"-"`
+		private  string //nolint:unused // Makes sure unexported fields are ignored
+	}
+
+	// Act
+	result := jsonFieldsOf(reflect.TypeOf(outer{}))
+
+	// Assert
+	expected := map[string]string{
+		"id":       "ID",
+		"name":     "Name",
+		"Other":    "Other",
+		"Untagged": "Untagged",
+	}
+
+	assert.Equal(t, expected, result)
+}
@@ -0,0 +1,82 @@
+package gohateoas
+
+// halLink is a link object as described in the HAL specification
+type halLink struct {
+	Href        string `json:"href"`
+	Templated   bool   `json:"templated,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Deprecation string `json:"deprecation,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Title       string `json:"title,omitempty"`
+}
+
+// AsHAL renders links in the HAL (application/hal+json) format. Links are grouped by relation,
+// links that share a relation through LinkInfo.Rel are rendered as an array. Nested objects
+// that have links registered are moved to the _embedded property.
+func AsHAL() InjectOption {
+	return func(config *injectConfig) {
+		config.renderer = halRenderer{}
+	}
+}
+
+// halRenderer renders resources according to the HAL specification
+type halRenderer struct{}
+
+func (halRenderer) RenderLinks(resource *Resource) {
+	if len(resource.Links) > 0 {
+		resource.Result["_links"] = halLinksOf(resource.Links)
+	}
+
+	// Nested resources are embedded regardless of the links of the parent, which may all be left out
+	if len(resource.Embedded) == 0 {
+		return
+	}
+
+	embedded := make(map[string]any, len(resource.Embedded))
+
+	for _, jsonKey := range resource.Embedded {
+		embedded[jsonKey] = resource.Result[jsonKey]
+		delete(resource.Result, jsonKey)
+	}
+
+	resource.Result["_embedded"] = embedded
+}
+
+// halLinksOf groups the links by their relation and converts them to HAL link objects
+func halLinksOf(links map[string]LinkInfo) map[string]any {
+	actions := sortedActions(links)
+
+	relations := map[string][]halLink{}
+
+	for _, action := range actions {
+		linkInfo := links[action]
+
+		relation := linkInfo.Rel
+		if relation == "" {
+			relation = action
+		}
+
+		relations[relation] = append(relations[relation], halLink{
+			Href:        linkInfo.Href,
+			Templated:   linkInfo.Templated,
+			Type:        linkInfo.Type,
+			Deprecation: linkInfo.Deprecation,
+			Name:        linkInfo.Name,
+			Title:       linkInfo.Comment,
+		})
+	}
+
+	result := make(map[string]any, len(relations))
+
+	for relation, halLinks := range relations {
+		if len(halLinks) == 1 {
+			result[relation] = halLinks[0]
+
+			continue
+		}
+
+		result[relation] = halLinks
+	}
+
+	return result
+}
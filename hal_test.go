@@ -0,0 +1,201 @@
+package gohateoas
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInjectLinksWith_AsHALCreatesExpectedJson(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		input    any
+		expected any
+	}{
+		"simple": {
+			input: &bakery{ID: 234},
+			expected: map[string]any{
+				"id": float64(234),
+				"_links": map[string]any{
+					"self": map[string]any{"href": "/api/v1/bakeries/234", "title": "get a bakery by id"},
+					"post": map[string]any{"href": "/api/v1/bakeries", "title": "create a new bakery"},
+				},
+			},
+		},
+		"deep object": {
+			input: &bakery{
+				ID:      234,
+				Cupcake: &cupcake{ID: 123, Name: "abc"},
+			},
+			expected: map[string]any{
+				"id": float64(234),
+				"_embedded": map[string]any{
+					"cupcake": map[string]any{
+						"id":     float64(123),
+						"name":   "abc",
+						"bakery": nil,
+						"_links": map[string]any{
+							"self":   map[string]any{"href": "/api/v1/cupcakes/123", "title": "get itself"},
+							"search": map[string]any{"href": "/api/v1/cupcakes{?query}", "templated": true, "type": "application/json"},
+						},
+					},
+				},
+				"_links": map[string]any{
+					"self": map[string]any{"href": "/api/v1/bakeries/234", "title": "get a bakery by id"},
+					"post": map[string]any{"href": "/api/v1/bakeries", "title": "create a new bakery"},
+				},
+			},
+		},
+		"deep array": {
+			input: []*bakery{
+				{
+					ID: 234,
+					Cupcakes: []*cupcake{
+						{ID: 1, Name: "a"},
+					},
+				},
+			},
+			expected: []any{
+				map[string]any{
+					"id": float64(234),
+					"_embedded": map[string]any{
+						"cupcakes": []any{
+							map[string]any{
+								"id":     float64(1),
+								"name":   "a",
+								"bakery": nil,
+								"_links": map[string]any{
+									"self":   map[string]any{"href": "/api/v1/cupcakes/1", "title": "get itself"},
+									"search": map[string]any{"href": "/api/v1/cupcakes{?query}", "templated": true, "type": "application/json"},
+								},
+							},
+						},
+					},
+					"_links": map[string]any{
+						"self": map[string]any{"href": "/api/v1/bakeries/234", "title": "get a bakery by id"},
+						"post": map[string]any{"href": "/api/v1/bakeries", "title": "create a new bakery"},
+					},
+				},
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+
+			RegisterOn(registry, &cupcake{},
+				Self("/api/v1/cupcakes/{id}", "get itself"),
+				Custom("search", LinkInfo{Method: http.MethodGet, Href: "/api/v1/cupcakes{?query}", Type: "application/json"}))
+
+			RegisterOn(registry, &bakery{},
+				Self("/api/v1/bakeries/{id}", "get a bakery by id"),
+				Post("/api/v1/bakeries", "create a new bakery"))
+
+			// Act
+			result := InjectLinksWith(registry, testData.input, AsHAL())
+
+			// Assert
+			var jsonResult any
+			_ = json.Unmarshal(result, &jsonResult)
+			assert.Equal(t, testData.expected, jsonResult)
+		})
+	}
+}
+
+func TestInjectLinksWith_AsHALRendersSharedRelationsAsArray(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	type halType1 struct {
+		ID int `json:"id"`
+	}
+
+	registry := NewLinkRegistry()
+
+	RegisterOn(registry, halType1{},
+		Self("/api/v1/things/{id}", "get itself"),
+		Custom("v1", LinkInfo{Rel: "alternate", Href: "/api/v1/things/{id}", Name: "v1", Deprecation: "/docs/v1"}),
+		Custom("v2", LinkInfo{Rel: "alternate", Href: "/api/v2/things/{id}", Name: "v2"}))
+
+	// Act
+	result := InjectLinksWith(registry, halType1{ID: 5}, AsHAL())
+
+	// Assert
+	expected := map[string]any{
+		"id": float64(5),
+		"_links": map[string]any{
+			"self": map[string]any{"href": "/api/v1/things/5", "title": "get itself"},
+			"alternate": []any{
+				map[string]any{"href": "/api/v1/things/5", "name": "v1", "deprecation": "/docs/v1"},
+				map[string]any{"href": "/api/v2/things/5", "name": "v2"},
+			},
+		},
+	}
+
+	var mapResult map[string]any
+	_ = json.Unmarshal(result, &mapResult)
+	assert.Equal(t, expected, mapResult)
+}
+
+func TestInjectLinksWith_DefaultsToLinkInfo(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, &bakery{}, Self("/api/v1/bakeries/{id}", "get a bakery by id"))
+
+	input := &bakery{ID: 3, Cupcake: &cupcake{ID: 4}}
+
+	// Act
+	result := InjectLinksWith(registry, input)
+
+	// Assert
+	assert.Equal(t, InjectLinks(registry, input), result)
+}
+
+func TestInjectLinksWith_AsHALEmbedsWithoutParentLinks(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	type halWrapper struct {
+		Data *cupcake `json:"data"`
+	}
+
+	registry := NewLinkRegistry()
+	RegisterOn(registry, &bakery{}, Custom("admin", LinkInfo{Method: http.MethodDelete, Href: "/api/v1/bakeries/{id}"}))
+	RegisterOn(registry, &cupcake{}, Self("/api/v1/cupcakes/{id}", "get itself"))
+
+	denyDeletes := AuthorizerFunc(func(_ context.Context, _ any, _ string, link LinkInfo) bool {
+		return link.Method != http.MethodDelete
+	})
+
+	tests := map[string]struct {
+		input    any
+		expected string
+	}{
+		"all links denied": {
+			input:    &bakery{ID: 1, Cupcake: &cupcake{ID: 2}},
+			expected: `{"id":1,"_embedded":{"cupcake":{"id":2,"name":"","bakery":null,"_links":{"self":{"href":"/api/v1/cupcakes/2","title":"get itself"}}}}}`,
+		},
+		"unregistered parent": {
+			input:    halWrapper{Data: &cupcake{ID: 2}},
+			expected: `{"_embedded":{"data":{"id":2,"name":"","bakery":null,"_links":{"self":{"href":"/api/v1/cupcakes/2","title":"get itself"}}}}}`,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Act
+			result := InjectLinksWith(registry, testData.input, AsHAL(), WithAuthorizer(denyDeletes))
+
+			// Assert
+			assert.JSONEq(t, testData.expected, string(result))
+		})
+	}
+}
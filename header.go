@@ -0,0 +1,152 @@
+package gohateoas
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// LinkHeader resolves the links of the object and formats them as the value of an RFC 8288 Link
+// header. Only the links of the object itself are used, nested objects and slices are ignored. Options
+// like WithBaseURL or WithVariables are used to resolve the links, renderers are ignored.
+func LinkHeader(registry *LinkRegistry, object any, options ...InjectOption) string {
+	config := newInjectConfig(registry, options...)
+
+	if len(config.links) == 0 || ensureConcrete(reflect.ValueOf(object)).Kind() != reflect.Struct {
+		return ""
+	}
+
+	rawJson, err := json.Marshal(object)
+	if err != nil {
+		return ""
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(rawJson, &result); err != nil {
+		return ""
+	}
+
+	return FormatLinkHeader(resolveLinks(config, object, result))
+}
+
+// FormatLinkHeader formats links as the value of an RFC 8288 Link header, like
+// `</api/v1/cupcakes/1>; rel="self"; title="Get this cupcake"`. The comment of a link is used as the
+// title, Type as the type and Params are added as target attributes. Links that are Templated are left
+// out, since a URI template is not a valid link target.
+func FormatLinkHeader(links map[string]LinkInfo) string {
+	actions := sortedActions(links)
+
+	values := make([]string, 0, len(actions))
+
+	for _, action := range actions {
+		linkInfo := links[action]
+		if linkInfo.Templated {
+			continue
+		}
+
+		relation := linkInfo.Rel
+		if relation == "" {
+			relation = action
+		}
+
+		value := fmt.Sprintf("<%s>; rel=%s", escapeLinkTarget(linkInfo.Href), quoteLinkParam(relation))
+
+		if linkInfo.Comment != "" {
+			value += formatLinkParam("title", linkInfo.Comment)
+		}
+
+		if linkInfo.Type != "" {
+			value += formatLinkParam("type", linkInfo.Type)
+		}
+
+		// Sort the parameters to make sure the header is always in the same order
+		params := make([]string, 0, len(linkInfo.Params))
+		for param := range linkInfo.Params {
+			params = append(params, param)
+		}
+
+		sort.Strings(params)
+
+		for _, param := range params {
+			value += formatLinkParam(param, linkInfo.Params[param])
+		}
+
+		values = append(values, value)
+	}
+
+	return strings.Join(values, ", ")
+}
+
+// linkTargetReplacer escapes the characters that may not appear in the target of a link
+var linkTargetReplacer = strings.NewReplacer("<", "%3C", ">", "%3E", " ", "%20", `"`, "%22")
+
+// escapeLinkTarget makes sure the href can be safely placed between angle brackets
+func escapeLinkTarget(href string) string {
+	return linkTargetReplacer.Replace(href)
+}
+
+// formatLinkParam formats a single target attribute. Values of parameters ending with a * or containing
+// non-ascii characters are encoded according to RFC 8187.
+func formatLinkParam(name string, value string) string {
+	if strings.HasSuffix(name, "*") {
+		return fmt.Sprintf("; %s=UTF-8''%s", name, encodeExtValue(value))
+	}
+
+	if !isASCII(value) {
+		return fmt.Sprintf("; %s*=UTF-8''%s", name, encodeExtValue(value))
+	}
+
+	return fmt.Sprintf("; %s=%s", name, quoteLinkParam(value))
+}
+
+// quoteReplacer escapes the characters that have a special meaning in a quoted-string
+var quoteReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// quoteLinkParam returns the value as a quoted-string
+func quoteLinkParam(value string) string {
+	return `"` + quoteReplacer.Replace(value) + `"`
+}
+
+// encodeExtValue percent-encodes every byte of the value that is not an attr-char, as described in RFC 8187
+func encodeExtValue(value string) string {
+	var builder strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		character := value[i]
+
+		if isAttrChar(character) {
+			builder.WriteByte(character)
+
+			continue
+		}
+
+		_, _ = fmt.Fprintf(&builder, "%%%02X", character)
+	}
+
+	return builder.String()
+}
+
+// isAttrChar returns true if the character may appear unencoded in an RFC 8187 ext-value
+func isAttrChar(character byte) bool {
+	switch {
+	case 'a' <= character && character <= 'z', 'A' <= character && character <= 'Z', '0' <= character && character <= '9':
+		return true
+
+	default:
+		return strings.IndexByte("!#$&+-.^_`|~", character) >= 0
+	}
+}
+
+// isASCII returns true if the value only contains ascii characters
+func isASCII(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
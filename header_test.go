@@ -0,0 +1,113 @@
+package gohateoas
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatLinkHeader_ReturnsExpectedValue(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		links    map[string]LinkInfo
+		expected string
+	}{
+		"nil": {},
+		"simple": {
+			links: map[string]LinkInfo{
+				"self": {Method: http.MethodGet, Href: "/api/v1/cupcakes/1"},
+			},
+			expected: `</api/v1/cupcakes/1>; rel="self"`,
+		},
+		"title and type": {
+			links: map[string]LinkInfo{
+				"self": {Href: "/api/v1/cupcakes/1", Comment: "Get this cupcake", Type: "application/json"},
+			},
+			expected: `</api/v1/cupcakes/1>; rel="self"; title="Get this cupcake"; type="application/json"`,
+		},
+		"sorted by action": {
+			links: map[string]LinkInfo{
+				"self":  {Href: "/a"},
+				"index": {Href: "/b"},
+				"other": {Href: "/c", Rel: "alternate"},
+			},
+			expected: `</b>; rel="index", </c>; rel="alternate", </a>; rel="self"`,
+		},
+		"quoting": {
+			links: map[string]LinkInfo{
+				"self": {Href: "/a b/<c>", Comment: `say "hi" \o/`},
+			},
+			expected: `</a%20b/%3Cc%3E>; rel="self"; title="say \"hi\" \\o/"`,
+		},
+		"non-ascii title": {
+			links: map[string]LinkInfo{
+				"self": {Href: "/a", Comment: "€ rates"},
+			},
+			expected: `</a>; rel="self"; title*=UTF-8''%E2%82%AC%20rates`,
+		},
+		"templated": {
+			links: map[string]LinkInfo{
+				"self":  {Href: "/a"},
+				"index": {Href: "/a{?page,size}", Templated: true},
+			},
+			expected: `</a>; rel="self"`,
+		},
+		"extension parameters": {
+			links: map[string]LinkInfo{
+				"self": {Href: "/a", Params: map[string]string{"hreflang": "nl", "method": "GET", "title*": "a;b"}},
+			},
+			expected: `</a>; rel="self"; hreflang="nl"; method="GET"; title*=UTF-8''a%3Bb`,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Act
+			result := FormatLinkHeader(testData.links)
+
+			// Assert
+			assert.Equal(t, testData.expected, result)
+		})
+	}
+}
+
+func TestLinkHeader_ResolvesLinksOfObject(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		input    any
+		expected string
+	}{
+		"object": {
+			input:    &bakery{ID: 5, Cupcake: &cupcake{ID: 6}},
+			expected: `</api/v1/bakeries>; rel="index", </api/v1/bakeries/5>; rel="self"; title="get itself"`,
+		},
+		"slice": {
+			input: []*bakery{{ID: 5}},
+		},
+		"unregistered": {
+			input: &cupcake{ID: 6},
+		},
+		"non-struct": {
+			input: "test",
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+			RegisterOn(registry, &bakery{}, Self("/api/v1/bakeries/{id}", "get itself"), Index("/api/v1/bakeries", ""))
+
+			// Act
+			result := LinkHeader(registry, testData.input)
+
+			// Assert
+			assert.Equal(t, testData.expected, result)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package gohateoas
+
+// hydraNamespace is the vocabulary used for hydra terms
+const hydraNamespace = "http://www.w3.org/ns/hydra/core#"
+
+// AsHydra renders registered structs as Hydra (application/ld+json) resources. The @id is taken
+// from the self link, the @type from the type name and every other link is added as a
+// hydra:operation with the method of the link.
+func AsHydra() InjectOption {
+	return func(config *injectConfig) {
+		config.renderer = hydraRenderer{}
+	}
+}
+
+// hydraOperation is an operation as described in the Hydra core vocabulary
+type hydraOperation struct {
+	Type   string `json:"@type"`
+	Method string `json:"hydra:method"`
+	Title  string `json:"hydra:title,omitempty"`
+	Target string `json:"hydra:target,omitempty"`
+}
+
+// hydraRenderer renders resources according to the Hydra core vocabulary
+type hydraRenderer struct{}
+
+func (hydraRenderer) RenderLinks(resource *Resource) {
+	if !resource.Registered {
+		return
+	}
+
+	resource.Result["@type"] = resourceTypeOf(resource.Object)
+
+	self, hasSelf := resource.Links["self"]
+	if hasSelf {
+		resource.Result["@id"] = self.Href
+	}
+
+	actions := sortedActions(resource.Links)
+
+	operations := make([]hydraOperation, 0, len(actions))
+
+	for _, action := range actions {
+		linkInfo := resource.Links[action]
+
+		if action == "self" {
+			continue
+		}
+
+		operation := hydraOperation{
+			Type:   "hydra:Operation",
+			Method: linkInfo.Method,
+			Title:  linkInfo.Comment,
+		}
+
+		// Operations apply to the resource itself, unless they point somewhere else
+		if !hasSelf || linkInfo.Href != self.Href {
+			operation.Target = linkInfo.Href
+		}
+
+		operations = append(operations, operation)
+	}
+
+	if len(operations) > 0 {
+		resource.Result["hydra:operation"] = operations
+	}
+}
+
+func (hydraRenderer) RenderDocument(result any) any {
+	context := map[string]any{"hydra": hydraNamespace}
+
+	switch result := result.(type) {
+	case map[string]any:
+		result["@context"] = context
+
+		return result
+
+	case []any:
+		return map[string]any{
+			"@context":         context,
+			"@type":            "hydra:Collection",
+			"hydra:member":     result,
+			"hydra:totalItems": len(result),
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,84 @@
+package gohateoas
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInjectLinksWith_AsHydraCreatesExpectedJson(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		input    any
+		expected map[string]any
+	}{
+		"single object": {
+			input: &bakery{ID: 3, Cupcake: &cupcake{ID: 1, Name: "a"}},
+			expected: map[string]any{
+				"@context": map[string]any{"hydra": "http://www.w3.org/ns/hydra/core#"},
+				"@id":      "/api/v1/bakeries/3",
+				"@type":    "bakery",
+				"id":       float64(3),
+				"cupcake": map[string]any{
+					"@id":    "/api/v1/cupcakes/1",
+					"@type":  "cupcake",
+					"id":     float64(1),
+					"name":   "a",
+					"bakery": nil,
+					"hydra:operation": []any{
+						map[string]any{"@type": "hydra:Operation", "hydra:method": "DELETE", "hydra:title": "delete it"},
+					},
+				},
+				"hydra:operation": []any{
+					map[string]any{"@type": "hydra:Operation", "hydra:method": "POST", "hydra:title": "bake one", "hydra:target": "/api/v1/bakeries/3/cupcakes"},
+				},
+			},
+		},
+		"slice": {
+			input: []cupcake{{ID: 1, Name: "a"}},
+			expected: map[string]any{
+				"@context": map[string]any{"hydra": "http://www.w3.org/ns/hydra/core#"},
+				"@type":    "hydra:Collection",
+				"hydra:member": []any{
+					map[string]any{
+						"@id":    "/api/v1/cupcakes/1",
+						"@type":  "cupcake",
+						"id":     float64(1),
+						"name":   "a",
+						"bakery": nil,
+						"hydra:operation": []any{
+							map[string]any{"@type": "hydra:Operation", "hydra:method": "DELETE", "hydra:title": "delete it"},
+						},
+					},
+				},
+				"hydra:totalItems": float64(1),
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+
+			RegisterOn(registry, &cupcake{},
+				Self("/api/v1/cupcakes/{id}", "get itself"),
+				Delete("/api/v1/cupcakes/{id}", "delete it"))
+
+			RegisterOn(registry, &bakery{},
+				Self("/api/v1/bakeries/{id}", "get a bakery"),
+				Post("/api/v1/bakeries/{id}/cupcakes", "bake one"))
+
+			// Act
+			result := InjectLinksWith(registry, testData.input, AsHydra())
+
+			// Assert
+			var mapResult map[string]any
+			_ = json.Unmarshal(result, &mapResult)
+			assert.Equal(t, testData.expected, mapResult)
+		})
+	}
+}
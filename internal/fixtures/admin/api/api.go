@@ -0,0 +1,8 @@
+// Package api contains a User type that has the same name as the one in the shop fixtures,
+// used to test that registrations of types with the same name don't collide.
+package api
+
+// User is an administrator
+type User struct {
+	Name string `json:"name"`
+}
@@ -0,0 +1,8 @@
+// Package api contains a User type that has the same name as the one in the admin fixtures,
+// used to test that registrations of types with the same name don't collide.
+package api
+
+// User is a customer of the shop
+type User struct {
+	ID int `json:"id"`
+}
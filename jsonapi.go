@@ -0,0 +1,253 @@
+package gohateoas
+
+import "strconv"
+
+// ResourceTyper can be implemented by registered types to override the name of the type
+// in formats like JSON:API. By default, the name of the type without its package is used.
+type ResourceTyper interface {
+	ResourceType() string
+}
+
+// resourceTypeOf returns the name of the type of the object used in the output
+func resourceTypeOf(object any) string {
+	if typer, ok := object.(ResourceTyper); ok {
+		return typer.ResourceType()
+	}
+
+	return shortTypeNameOf(object)
+}
+
+// AsJSONAPI renders registered structs as JSON:API (application/vnd.api+json) resource objects with
+// a type, id, attributes, relationships and links. Resources without an id get a local id (lid). Nested objects that have links registered become
+// relationships. The document is wrapped in a top-level data property.
+func AsJSONAPI() InjectOption {
+	return func(config *injectConfig) {
+		config.renderer = &jsonAPIRenderer{}
+	}
+}
+
+// AsCompoundJSONAPI is similar to AsJSONAPI, but also adds the resource objects of all relationships
+// to the top-level included property, making it a compound document.
+func AsCompoundJSONAPI() InjectOption {
+	return func(config *injectConfig) {
+		config.renderer = &jsonAPIRenderer{compound: true, includedKeys: map[string]bool{}}
+	}
+}
+
+// jsonAPILink is a link object as described in the JSON:API specification
+type jsonAPILink struct {
+	Href  string         `json:"href"`
+	Title string         `json:"title,omitempty"`
+	Type  string         `json:"type,omitempty"`
+	Meta  map[string]any `json:"meta,omitempty"`
+}
+
+// jsonAPIRelationship is a relationship object as described in the JSON:API specification
+type jsonAPIRelationship struct {
+	Data  any               `json:"data"`
+	Links map[string]string `json:"links,omitempty"`
+}
+
+// jsonAPIRenderer renders resources according to the JSON:API specification, it is stateful
+// and should only be used for a single document.
+type jsonAPIRenderer struct {
+	compound     bool
+	included     []any
+	includedKeys map[string]bool
+
+	// localIDs is the number of local ids handed out to resources without an id
+	localIDs int
+}
+
+func (j *jsonAPIRenderer) RenderLinks(resource *Resource) {
+	if !resource.Registered {
+		return
+	}
+
+	resourceObject := map[string]any{
+		"type": resourceTypeOf(resource.Object),
+	}
+
+	// Resources without an id get a local id, so relationships can still refer to them
+	if id, ok := resource.Result["id"]; ok && id != nil {
+		resourceObject["id"] = templateString(id)
+	} else {
+		j.localIDs++
+		resourceObject["lid"] = strconv.Itoa(j.localIDs)
+	}
+
+	if len(resource.Links) > 0 {
+		resourceObject["links"] = jsonAPILinksOf(resource.Links)
+	}
+
+	if relationships := j.relationshipsOf(resource); len(relationships) > 0 {
+		resourceObject["relationships"] = relationships
+	}
+
+	// Whatever is left over are the attributes of the resource
+	delete(resource.Result, "id")
+
+	if len(resource.Result) > 0 {
+		attributes := make(map[string]any, len(resource.Result))
+		for key, value := range resource.Result {
+			attributes[key] = value
+		}
+
+		resourceObject["attributes"] = attributes
+	}
+
+	// Replace the contents of the original map, that way the parent's reference stays intact
+	for key := range resource.Result {
+		delete(resource.Result, key)
+	}
+
+	for key, value := range resourceObject {
+		resource.Result[key] = value
+	}
+}
+
+// relationshipsOf removes the embedded objects from the resource and turns them into relationships
+func (j *jsonAPIRenderer) relationshipsOf(resource *Resource) map[string]jsonAPIRelationship {
+	relationships := make(map[string]jsonAPIRelationship, len(resource.Embedded))
+
+	for _, jsonKey := range resource.Embedded {
+		relationship := jsonAPIRelationship{
+			Links: relationshipLinksOf(resource.Links, jsonKey, resource.Result[jsonKey]),
+		}
+
+		switch value := resource.Result[jsonKey].(type) {
+		case map[string]any:
+			relationship.Data = j.identifierOf(value)
+
+		case []any:
+			identifiers := make([]any, 0, len(value))
+			for _, entry := range value {
+				if entry, ok := entry.(map[string]any); ok {
+					identifiers = append(identifiers, j.identifierOf(entry))
+				}
+			}
+
+			relationship.Data = identifiers
+		}
+
+		relationships[jsonKey] = relationship
+
+		delete(resource.Result, jsonKey)
+	}
+
+	return relationships
+}
+
+// identifierOf returns the resource identifier of a resource object and saves the resource object
+// as included if this is a compound document.
+func (j *jsonAPIRenderer) identifierOf(resourceObject map[string]any) map[string]any {
+	identifier := map[string]any{"type": resourceObject["type"]}
+
+	if lid, ok := resourceObject["lid"]; ok {
+		identifier["lid"] = lid
+	} else {
+		identifier["id"] = resourceObject["id"]
+	}
+
+	if !j.compound {
+		return identifier
+	}
+
+	// Resources with a local id are unique, so they're always included
+	if key, ok := resourceKeyOf(resourceObject); ok {
+		if j.includedKeys[key] {
+			return identifier
+		}
+
+		j.includedKeys[key] = true
+	}
+
+	j.included = append(j.included, resourceObject)
+
+	return identifier
+}
+
+// resourceKeyOf returns the type and id that identify the resource object, or false if it has no id
+func resourceKeyOf(resourceObject map[string]any) (string, bool) {
+	if resourceObject["id"] == nil {
+		return "", false
+	}
+
+	return templateString(resourceObject["type"]) + "/" + templateString(resourceObject["id"]), true
+}
+
+func (j *jsonAPIRenderer) RenderDocument(result any) any {
+	document := map[string]any{"data": result}
+
+	if !j.compound {
+		return document
+	}
+
+	// Primary data may not be included as well
+	primaryKeys := map[string]bool{}
+
+	primaryData, ok := result.([]any)
+	if !ok {
+		primaryData = []any{result}
+	}
+
+	for _, entry := range primaryData {
+		if entry, ok := entry.(map[string]any); ok {
+			if key, ok := resourceKeyOf(entry); ok {
+				primaryKeys[key] = true
+			}
+		}
+	}
+
+	included := make([]any, 0, len(j.included))
+
+	for _, entry := range j.included {
+		entry, _ := entry.(map[string]any)
+		if key, ok := resourceKeyOf(entry); !ok || !primaryKeys[key] {
+			included = append(included, entry)
+		}
+	}
+
+	document["included"] = included
+
+	return document
+}
+
+// jsonAPILinksOf converts the links to JSON:API link objects, the method is added as meta information
+func jsonAPILinksOf(links map[string]LinkInfo) map[string]jsonAPILink {
+	result := make(map[string]jsonAPILink, len(links))
+
+	for action, linkInfo := range links {
+		link := jsonAPILink{
+			Href:  linkInfo.Href,
+			Title: linkInfo.Comment,
+			Type:  linkInfo.Type,
+		}
+
+		if linkInfo.Method != "" {
+			link.Meta = map[string]any{"method": linkInfo.Method}
+		}
+
+		result[action] = link
+	}
+
+	return result
+}
+
+// relationshipLinksOf returns the links of a relationship. A link registered with the name of the relationship is
+// used as the related link, otherwise the self link of a single related resource is used. No links are made up,
+// since there's no telling whether anything is served there.
+func relationshipLinksOf(links map[string]LinkInfo, relationship string, resourceObject any) map[string]string {
+	if related, ok := links[relationship]; ok {
+		return map[string]string{"related": related.Href}
+	}
+
+	if resourceObject, ok := resourceObject.(map[string]any); ok {
+		resourceLinks, _ := resourceObject["links"].(map[string]jsonAPILink)
+		if self, ok := resourceLinks["self"]; ok {
+			return map[string]string{"related": self.Href}
+		}
+	}
+
+	return nil
+}
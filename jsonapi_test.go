@@ -0,0 +1,300 @@
+package gohateoas
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonAPIOven struct {
+	ID          int    `json:"id"`
+	Temperature int    `json:"temperature"`
+	Brand       string `json:"brand"`
+}
+
+func (jsonAPIOven) ResourceType() string {
+	return "ovens"
+}
+
+type jsonAPIKitchen struct {
+	ID       int           `json:"id"`
+	Name     string        `json:"name"`
+	Oven     *jsonAPIOven  `json:"oven,omitempty"`
+	Cupcakes []*cupcake    `json:"cupcakes,omitempty"`
+	Spare    []jsonAPIOven `json:"spare,omitempty"`
+}
+
+func TestResourceTypeOf_ReturnsExpectedName(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		input    any
+		expected string
+	}{
+		"type name": {
+			input:    &cupcake{},
+			expected: "cupcake",
+		},
+		"resource typer": {
+			input:    jsonAPIOven{},
+			expected: "ovens",
+		},
+		"resource typer pointer": {
+			input:    &jsonAPIOven{},
+			expected: "ovens",
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Act
+			result := resourceTypeOf(testData.input)
+
+			// Assert
+			assert.Equal(t, testData.expected, result)
+		})
+	}
+}
+
+func TestInjectLinksWith_AsJSONAPICreatesExpectedJson(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		input    any
+		expected map[string]any
+	}{
+		"simple": {
+			input: &jsonAPIOven{ID: 5, Temperature: 180, Brand: "abc"},
+			expected: map[string]any{
+				"data": map[string]any{
+					"type":       "ovens",
+					"id":         "5",
+					"attributes": map[string]any{"temperature": float64(180), "brand": "abc"},
+					"links": map[string]any{
+						"self": map[string]any{"href": "/api/v1/ovens/5", "title": "get an oven", "meta": map[string]any{"method": "GET"}},
+					},
+				},
+			},
+		},
+		"relationships": {
+			input: &jsonAPIKitchen{
+				ID:       3,
+				Name:     "main",
+				Oven:     &jsonAPIOven{ID: 5},
+				Cupcakes: []*cupcake{{ID: 1}, {ID: 2}},
+			},
+			expected: map[string]any{
+				"data": map[string]any{
+					"type":       "jsonAPIKitchen",
+					"id":         "3",
+					"attributes": map[string]any{"name": "main"},
+					"links": map[string]any{
+						"self":     map[string]any{"href": "/api/v1/kitchens/3", "title": "get a kitchen", "meta": map[string]any{"method": "GET"}},
+						"cupcakes": map[string]any{"href": "/api/v1/cupcakes?kitchen=3", "meta": map[string]any{"method": "GET"}},
+					},
+					"relationships": map[string]any{
+						"oven": map[string]any{
+							"data": map[string]any{"type": "ovens", "id": "5"},
+							"links": map[string]any{
+								"related": "/api/v1/ovens/5",
+							},
+						},
+						"cupcakes": map[string]any{
+							"data": []any{
+								map[string]any{"type": "cupcake", "id": "1"},
+								map[string]any{"type": "cupcake", "id": "2"},
+							},
+							"links": map[string]any{
+								"related": "/api/v1/cupcakes?kitchen=3",
+							},
+						},
+					},
+				},
+			},
+		},
+		"relationship without links": {
+			input: &jsonAPIKitchen{ID: 3, Spare: []jsonAPIOven{{ID: 6}}},
+			expected: map[string]any{
+				"data": map[string]any{
+					"type":       "jsonAPIKitchen",
+					"id":         "3",
+					"attributes": map[string]any{"name": ""},
+					"links": map[string]any{
+						"self":     map[string]any{"href": "/api/v1/kitchens/3", "title": "get a kitchen", "meta": map[string]any{"method": "GET"}},
+						"cupcakes": map[string]any{"href": "/api/v1/cupcakes?kitchen=3", "meta": map[string]any{"method": "GET"}},
+					},
+					"relationships": map[string]any{
+						"spare": map[string]any{
+							"data": []any{map[string]any{"type": "ovens", "id": "6"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+
+			RegisterOn(registry, &cupcake{}, Self("/api/v1/cupcakes/{id}", "get itself"))
+			RegisterOn(registry, &jsonAPIOven{}, Self("/api/v1/ovens/{id}", "get an oven"))
+			RegisterOn(registry, &jsonAPIKitchen{},
+				Self("/api/v1/kitchens/{id}", "get a kitchen"),
+				Custom("cupcakes", LinkInfo{Method: "GET", Href: "/api/v1/cupcakes?kitchen={id}"}))
+
+			// Act
+			result := InjectLinksWith(registry, testData.input, AsJSONAPI())
+
+			// Assert
+			var mapResult map[string]any
+			_ = json.Unmarshal(result, &mapResult)
+			assert.Equal(t, testData.expected, mapResult)
+		})
+	}
+}
+
+func TestInjectLinksWith_AsCompoundJSONAPIIncludesUniqueResources(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+
+	RegisterOn(registry, &jsonAPIOven{}, Self("/api/v1/ovens/{id}", "get an oven"))
+	RegisterOn(registry, &jsonAPIKitchen{}, Self("/api/v1/kitchens/{id}", "get a kitchen"))
+
+	input := []jsonAPIKitchen{
+		{ID: 1, Oven: &jsonAPIOven{ID: 5, Brand: "a"}, Spare: []jsonAPIOven{{ID: 6, Brand: "b"}}},
+		{ID: 2, Oven: &jsonAPIOven{ID: 5, Brand: "a"}},
+	}
+
+	// Act
+	result := InjectLinksWith(registry, input, AsCompoundJSONAPI())
+
+	// Assert
+	var mapResult map[string]any
+	_ = json.Unmarshal(result, &mapResult)
+
+	ovenLinks := func(id string) map[string]any {
+		return map[string]any{
+			"self": map[string]any{"href": "/api/v1/ovens/" + id, "title": "get an oven", "meta": map[string]any{"method": "GET"}},
+		}
+	}
+
+	expectedIncluded := []any{
+		map[string]any{
+			"type":       "ovens",
+			"id":         "5",
+			"attributes": map[string]any{"temperature": float64(0), "brand": "a"},
+			"links":      ovenLinks("5"),
+		},
+		map[string]any{
+			"type":       "ovens",
+			"id":         "6",
+			"attributes": map[string]any{"temperature": float64(0), "brand": "b"},
+			"links":      ovenLinks("6"),
+		},
+	}
+
+	if assert.Len(t, mapResult["data"], 2) {
+		assert.ElementsMatch(t, expectedIncluded, mapResult["included"])
+	}
+}
+
+func TestInjectLinksWith_AsCompoundJSONAPIDoesNotIncludePrimaryData(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, &bakery{}, Self("/api/v1/bakeries/{id}", "get a bakery"))
+	RegisterOn(registry, &cupcake{}, Self("/api/v1/cupcakes/{id}", "get a cupcake"))
+
+	input := []*bakery{
+		{ID: 1, Cupcake: &cupcake{ID: 2, Bakery: &bakery{ID: 1}}},
+	}
+
+	// Act
+	result := InjectLinksWith(registry, input, AsCompoundJSONAPI())
+
+	// Assert
+	var mapResult map[string]any
+	_ = json.Unmarshal(result, &mapResult)
+
+	included, _ := mapResult["included"].([]any)
+	if assert.Len(t, included, 1) {
+		assert.Equal(t, "cupcake", included[0].(map[string]any)["type"])
+	}
+}
+
+type jsonAPINote struct {
+	Text string `json:"text"`
+}
+
+type jsonAPINotebook struct {
+	ID    int           `json:"id"`
+	Notes []jsonAPINote `json:"notes"`
+}
+
+func TestInjectLinksWith_AsCompoundJSONAPIIncludesResourcesWithoutID(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, &jsonAPINotebook{}, Self("/api/v1/notebooks/{id}", "get a notebook"))
+	RegisterOn(registry, &jsonAPINote{}, Index("/api/v1/notes", "get all notes"))
+
+	input := jsonAPINotebook{ID: 1, Notes: []jsonAPINote{{Text: "a"}, {Text: "b"}}}
+
+	// Act
+	result := InjectLinksWith(registry, input, AsCompoundJSONAPI())
+
+	// Assert
+	var mapResult map[string]any
+	_ = json.Unmarshal(result, &mapResult)
+
+	included, _ := mapResult["included"].([]any)
+	if assert.Len(t, included, 2) {
+		assert.Equal(t, map[string]any{"text": "a"}, included[0].(map[string]any)["attributes"])
+		assert.Equal(t, map[string]any{"text": "b"}, included[1].(map[string]any)["attributes"])
+
+		// The relationship refers to the included resources using their local ids
+		data, _ := mapResult["data"].(map[string]any)
+		relationships, _ := data["relationships"].(map[string]any)
+		notes, _ := relationships["notes"].(map[string]any)
+
+		expected := []any{
+			map[string]any{"type": "jsonAPINote", "lid": included[0].(map[string]any)["lid"]},
+			map[string]any{"type": "jsonAPINote", "lid": included[1].(map[string]any)["lid"]},
+		}
+
+		assert.Equal(t, expected, notes["data"])
+		assert.NotEqual(t, included[0].(map[string]any)["lid"], included[1].(map[string]any)["lid"])
+		assert.NotContains(t, included[0], "id")
+	}
+}
+
+func TestInjectLinksWith_AsJSONAPIFormatsLargeIDs(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, &jsonAPIOven{}, Self("/api/v1/ovens/{id}", "get an oven"))
+	RegisterOn(registry, &jsonAPIKitchen{}, Self("/api/v1/kitchens/{id}", "get a kitchen"))
+
+	input := jsonAPIKitchen{ID: 12345678, Oven: &jsonAPIOven{ID: 87654321}}
+
+	// Act
+	result := InjectLinksWith(registry, input, AsCompoundJSONAPI())
+
+	// Assert
+	var mapResult map[string]any
+	_ = json.Unmarshal(result, &mapResult)
+
+	data, _ := mapResult["data"].(map[string]any)
+	assert.Equal(t, "12345678", data["id"])
+
+	included, _ := mapResult["included"].([]any)
+	if assert.Len(t, included, 1) {
+		assert.Equal(t, "87654321", included[0].(map[string]any)["id"])
+	}
+}
@@ -0,0 +1,318 @@
+package gohateoas
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Format couples a media type to the options that render it
+type Format struct {
+	// MediaType is matched against the Accept header and used as the Content-Type
+	MediaType string
+
+	// Options are passed to InjectLinksWith when this format is selected
+	Options []InjectOption
+}
+
+// DefaultFormats are the formats supported by Negotiate if none are configured, the first
+// format is used if the client does not send an Accept header.
+var DefaultFormats = []Format{
+	{MediaType: "application/json"},
+	{MediaType: "application/hal+json", Options: []InjectOption{AsHAL()}},
+	{MediaType: "application/vnd.api+json", Options: []InjectOption{AsJSONAPI()}},
+	{MediaType: "application/vnd.siren+json", Options: []InjectOption{AsSiren()}},
+	{MediaType: "application/vnd.collection+json", Options: []InjectOption{AsCollectionJSON()}},
+	{MediaType: "application/ld+json", Options: []InjectOption{AsHydra()}},
+}
+
+// NegotiationOption is used to configure Negotiate
+type NegotiationOption func(*negotiationConfig)
+
+// negotiationConfig contains the settings of Negotiate
+type negotiationConfig struct {
+	registry       *LinkRegistry
+	formats        []Format
+	injectOptions  []InjectOption
+	linkHeader     bool
+	linkHeaderOnly bool
+	requestBaseURL bool
+}
+
+// newNegotiationConfig returns the config with defaults, overridden by the given options
+func newNegotiationConfig(options ...NegotiationOption) *negotiationConfig {
+	config := &negotiationConfig{
+		registry: DefaultLinkRegistry,
+		formats:  DefaultFormats,
+	}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	return config
+}
+
+// negotiableFormats returns the formats Negotiate selects from. If the links are only sent in a header the body
+// is plain json, so formats that render it differently can't be selected.
+func (n *negotiationConfig) negotiableFormats() []Format {
+	if !n.linkHeaderOnly {
+		return n.formats
+	}
+
+	formats := make([]Format, 0, len(n.formats))
+
+	for _, format := range n.formats {
+		if _, ok := newInjectConfig(n.registry, format.Options...).renderer.(PropertyRenderer); ok {
+			formats = append(formats, format)
+		}
+	}
+
+	return formats
+}
+
+// UsingRegistry makes Negotiate use the given registry instead of the DefaultLinkRegistry
+func UsingRegistry(registry *LinkRegistry) NegotiationOption {
+	return func(config *negotiationConfig) {
+		config.registry = registry
+	}
+}
+
+// SupportedFormats replaces the DefaultFormats supported by Negotiate
+func SupportedFormats(formats ...Format) NegotiationOption {
+	return func(config *negotiationConfig) {
+		config.formats = formats
+	}
+}
+
+// WithInjectOptions passes the options to every InjectLinksWith call made by Respond, before the
+// options of the format. This can be used to set an UnresolvedTokenPolicy for example.
+func WithInjectOptions(options ...InjectOption) NegotiationOption {
+	return func(config *negotiationConfig) {
+		config.injectOptions = append(config.injectOptions, options...)
+	}
+}
+
+// WithLinkHeader makes Respond add the links of the value to an RFC 8288 Link header, in
+// addition to the links in the body.
+func WithLinkHeader() NegotiationOption {
+	return func(config *negotiationConfig) {
+		config.linkHeader = true
+	}
+}
+
+// WithLinkHeaderOnly makes Respond add the links of the value to an RFC 8288 Link header, instead
+// of the links in the body. The body is encoded as plain json, so only formats without a renderer,
+// like application/json, can be selected.
+func WithLinkHeaderOnly() NegotiationOption {
+	return func(config *negotiationConfig) {
+		config.linkHeader = true
+		config.linkHeaderOnly = true
+	}
+}
+
+// WithBaseURLFromRequest makes Respond use absolute links, with the base url derived from the request
+// using BaseURL. Only use this if your proxies overwrite the forwarded headers.
+func WithBaseURLFromRequest() NegotiationOption {
+	return func(config *negotiationConfig) {
+		config.requestBaseURL = true
+	}
+}
+
+// Negotiate is a middleware that selects a format based on the Accept header of the request. Handlers
+// can use Respond to encode a value in the selected format. If none of the formats are acceptable,
+// it responds with 406 Not Acceptable without calling the next handler. The decisions of an Authorizer
+// are cached for the duration of the request.
+func Negotiate(next http.Handler, options ...NegotiationOption) http.Handler {
+	config := newNegotiationConfig(options...)
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Add("Vary", "Accept")
+
+		format, ok := negotiateFormat(request.Header.Get("Accept"), config.negotiableFormats())
+		if !ok {
+			http.Error(writer, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+
+			return
+		}
+
+		request = request.WithContext(ContextWithAuthorizationCache(request.Context()))
+
+		negotiated := &negotiatedWriter{ResponseWriter: writer, format: format, config: config, ctx: request.Context()}
+
+		if config.requestBaseURL {
+			negotiated.baseURL = BaseURL(request)
+		}
+
+		next.ServeHTTP(negotiated, request)
+	})
+}
+
+// negotiatedWriter remembers the format that was selected by Negotiate
+type negotiatedWriter struct {
+	http.ResponseWriter
+
+	format  Format
+	config  *negotiationConfig
+	baseURL string
+
+	//nolint:containedctx // Respond has no access to the request
+	ctx context.Context
+}
+
+// Unwrap returns the original http.ResponseWriter, used by http.ResponseController
+func (n *negotiatedWriter) Unwrap() http.ResponseWriter {
+	return n.ResponseWriter
+}
+
+// Respond encodes the value in the format that was selected by Negotiate and writes it with the given
+// status code. The context of the request, as it passed through Negotiate, is used like InjectLinksContext
+// does. If the writer did not pass through Negotiate, the value is encoded as application/json
+// with links from the DefaultLinkRegistry. If the value can not be encoded, an EncodingError is returned
+// and nothing is written.
+func Respond(writer http.ResponseWriter, status int, value any) error {
+	negotiated := negotiatedWriterOf(writer)
+	if negotiated == nil {
+		negotiated = &negotiatedWriter{ResponseWriter: writer, format: DefaultFormats[0], config: newNegotiationConfig(), ctx: context.Background()}
+	}
+
+	registry := negotiated.config.registry
+
+	options := []InjectOption{withContext(negotiated.ctx)}
+	options = append(options, negotiated.config.injectOptions...)
+	if negotiated.baseURL != "" {
+		options = append(options, WithBaseURL(negotiated.baseURL))
+	}
+
+	var body []byte
+
+	var err error
+
+	if negotiated.config.linkHeaderOnly {
+		if body, err = json.Marshal(value); err != nil {
+			err = &EncodingError{Err: err}
+		}
+	} else {
+		body, err = marshalWithLinks(value, newInjectConfig(registry, append(options, negotiated.format.Options...)...))
+	}
+
+	// Nothing has been written yet, so the caller is still able to respond with an error
+	if err != nil {
+		return err
+	}
+
+	if negotiated.config.linkHeader {
+		if header := LinkHeader(registry, value, options...); header != "" {
+			writer.Header().Add("Link", header)
+		}
+	}
+
+	writer.Header().Set("Content-Type", negotiated.format.MediaType)
+	writer.WriteHeader(status)
+
+	_, err = writer.Write(body)
+
+	return err
+}
+
+// negotiatedWriterOf finds the negotiatedWriter in a chain of wrapped writers
+func negotiatedWriterOf(writer http.ResponseWriter) *negotiatedWriter {
+	for {
+		switch typedWriter := writer.(type) {
+		case *negotiatedWriter:
+			return typedWriter
+
+		case interface{ Unwrap() http.ResponseWriter }:
+			writer = typedWriter.Unwrap()
+
+		default:
+			return nil
+		}
+	}
+}
+
+// acceptRange is a media range of an Accept header with its quality
+type acceptRange struct {
+	mediaRange string
+	quality    float64
+}
+
+// parseAccept returns the media ranges of the Accept header, invalid ranges are ignored
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+
+	for _, rawRange := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(rawRange))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+
+		ranges = append(ranges, acceptRange{mediaRange: mediaRange, quality: quality})
+	}
+
+	return ranges
+}
+
+// negotiateFormat returns the format that best matches the Accept header. As described in RFC 7231,
+// every format gets the quality of the most specific media range that matches it, a quality of 0 means
+// the format is not acceptable. Ties are broken by the order of the formats.
+func negotiateFormat(accept string, formats []Format) (Format, bool) {
+	if len(formats) == 0 {
+		return Format{}, false
+	}
+
+	if strings.TrimSpace(accept) == "" {
+		return formats[0], true
+	}
+
+	ranges := parseAccept(accept)
+
+	var best Format
+
+	bestQuality := 0.0
+
+	for _, format := range formats {
+		quality, specificity := 0.0, -1
+
+		for _, mediaRange := range ranges {
+			if rangeSpecificity := mediaRangeSpecificity(mediaRange.mediaRange, format.MediaType); rangeSpecificity > specificity {
+				quality, specificity = mediaRange.quality, rangeSpecificity
+			}
+		}
+
+		// Formats are ordered by preference, so a later format needs a higher quality
+		if quality > bestQuality {
+			best, bestQuality = format, quality
+		}
+	}
+
+	return best, bestQuality > 0
+}
+
+// mediaRangeSpecificity returns how specifically the media range matches the media type, -1 if
+// it does not match at all, 0 for */*, 1 for type/* and 2 for an exact match.
+func mediaRangeSpecificity(mediaRange string, mediaType string) int {
+	switch {
+	case mediaRange == "*/*":
+		return 0
+
+	case strings.EqualFold(mediaRange, mediaType):
+		return 2
+
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+
+	default:
+		return -1
+	}
+}
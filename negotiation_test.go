@@ -0,0 +1,353 @@
+package gohateoas
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateFormat_ReturnsExpectedFormat(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		accept   string
+		expected string
+		ok       bool
+	}{
+		"empty": {
+			accept:   "",
+			expected: "application/json",
+			ok:       true,
+		},
+		"exact": {
+			accept:   "application/hal+json",
+			expected: "application/hal+json",
+			ok:       true,
+		},
+		"case insensitive": {
+			accept:   "Application/Vnd.Siren+JSON",
+			expected: "application/vnd.siren+json",
+			ok:       true,
+		},
+		"wildcard": {
+			accept:   "*/*",
+			expected: "application/json",
+			ok:       true,
+		},
+		"type wildcard": {
+			accept:   "text/html, application/*;q=0.5",
+			expected: "application/json",
+			ok:       true,
+		},
+		"quality": {
+			accept:   "application/hal+json;q=0.5, application/vnd.api+json",
+			expected: "application/vnd.api+json",
+			ok:       true,
+		},
+		"specificity": {
+			accept:   "*/*;q=0.9, application/ld+json",
+			expected: "application/ld+json",
+			ok:       true,
+		},
+		"equal quality": {
+			accept:   "*/*, application/ld+json",
+			expected: "application/json",
+			ok:       true,
+		},
+		"excluded by specific range": {
+			accept:   "application/json;q=0, */*",
+			expected: "application/hal+json",
+			ok:       true,
+		},
+		"most specific range wins": {
+			accept:   "application/*;q=0.1, application/hal+json;q=0.5, */*;q=0.9",
+			expected: "application/hal+json",
+			ok:       true,
+		},
+		"excluded by wildcard": {
+			accept: "*/*;q=0",
+		},
+		"zero quality": {
+			accept: "application/json;q=0",
+		},
+		"unsupported": {
+			accept: "text/html, image/png",
+		},
+		"invalid": {
+			accept: "application/json;q=abc, ;;",
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Act
+			result, ok := negotiateFormat(testData.accept, DefaultFormats)
+
+			// Assert
+			assert.Equal(t, testData.ok, ok)
+			assert.Equal(t, testData.expected, result.MediaType)
+		})
+	}
+}
+
+func TestNegotiate_RespondsInNegotiatedFormat(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		accept       string
+		expectedType string
+		expectedBody map[string]any
+	}{
+		"json": {
+			accept:       "application/json",
+			expectedType: "application/json",
+			expectedBody: map[string]any{
+				"id": float64(5),
+				"_links": map[string]any{
+					"self": map[string]any{"method": "GET", "href": "/api/v1/bakeries/5", "comment": "get itself"},
+				},
+			},
+		},
+		"hal": {
+			accept:       "application/hal+json",
+			expectedType: "application/hal+json",
+			expectedBody: map[string]any{
+				"id": float64(5),
+				"_links": map[string]any{
+					"self": map[string]any{"href": "/api/v1/bakeries/5", "title": "get itself"},
+				},
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+			RegisterOn(registry, &bakery{}, Self("/api/v1/bakeries/{id}", "get itself"))
+
+			handler := Negotiate(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+				_ = Respond(writer, http.StatusCreated, &bakery{ID: 5})
+			}), UsingRegistry(registry))
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Accept", testData.accept)
+
+			recorder := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(recorder, request)
+
+			// Assert
+			assert.Equal(t, http.StatusCreated, recorder.Code)
+			assert.Equal(t, testData.expectedType, recorder.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", recorder.Header().Get("Vary"))
+
+			var body map[string]any
+			_ = json.Unmarshal(recorder.Body.Bytes(), &body)
+			assert.Equal(t, testData.expectedBody, body)
+		})
+	}
+}
+
+func TestNegotiate_ReturnsNotAcceptable(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	called := false
+
+	handler := Negotiate(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		called = true
+	}), SupportedFormats(Format{MediaType: "application/hal+json", Options: []InjectOption{AsHAL()}}))
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Accept", "application/json")
+
+	recorder := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(recorder, request)
+
+	// Assert
+	assert.Equal(t, http.StatusNotAcceptable, recorder.Code)
+	assert.Equal(t, "Accept", recorder.Header().Get("Vary"))
+	assert.False(t, called)
+}
+
+// wrappedWriter mimics a writer of another middleware
+type wrappedWriter struct {
+	http.ResponseWriter
+}
+
+func (w wrappedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func TestRespond_FindsWrappedNegotiatedWriter(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, &bakery{}, Self("/api/v1/bakeries/{id}", "get itself"))
+
+	handler := Negotiate(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_ = Respond(wrappedWriter{ResponseWriter: writer}, http.StatusOK, &bakery{ID: 5})
+	}), UsingRegistry(registry))
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Accept", "application/vnd.siren+json")
+
+	recorder := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(recorder, request)
+
+	// Assert
+	assert.Equal(t, "application/vnd.siren+json", recorder.Header().Get("Content-Type"))
+}
+
+func TestRespond_DefaultsToJsonWithoutNegotiation(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	recorder := httptest.NewRecorder()
+
+	// Act
+	err := Respond(recorder, http.StatusOK, []string{"a"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, `["a"]`, recorder.Body.String())
+}
+
+func TestNegotiate_AddsLinkHeader(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		option       NegotiationOption
+		expectedBody string
+	}{
+		"in addition to body": {
+			option:       WithLinkHeader(),
+			expectedBody: `{"id": 5, "_links": {"self": {"method": "GET", "href": "/api/v1/bakeries/5", "comment": "get itself"}}}`,
+		},
+		"instead of body": {
+			option:       WithLinkHeaderOnly(),
+			expectedBody: `{"id": 5}`,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+			RegisterOn(registry, &bakery{}, Self("/api/v1/bakeries/{id}", "get itself"))
+
+			handler := Negotiate(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+				_ = Respond(writer, http.StatusOK, &bakery{ID: 5})
+			}), UsingRegistry(registry), testData.option)
+
+			recorder := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			// Assert
+			assert.Equal(t, `</api/v1/bakeries/5>; rel="self"; title="get itself"`, recorder.Header().Get("Link"))
+			assert.JSONEq(t, testData.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func TestNegotiate_OnlySelectsPlainFormatsWithLinkHeaderOnly(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		accept              string
+		expectedStatus      int
+		expectedContentType string
+	}{
+		"json": {
+			accept:              "application/hal+json, application/json;q=0.5",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+		},
+		"wildcard": {
+			accept:              "*/*",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+		},
+		"hal only": {
+			accept:              "application/hal+json",
+			expectedStatus:      http.StatusNotAcceptable,
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+			RegisterOn(registry, &bakery{}, Self("/api/v1/bakeries/{id}", "get itself"))
+
+			handler := Negotiate(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+				_ = Respond(writer, http.StatusOK, &bakery{ID: 5})
+			}), UsingRegistry(registry), WithLinkHeaderOnly())
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Accept", testData.accept)
+
+			recorder := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(recorder, request)
+
+			// Assert
+			assert.Equal(t, testData.expectedStatus, recorder.Code)
+			assert.Equal(t, testData.expectedContentType, recorder.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestRespond_ReturnsEncodingErrorWithoutWriting(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	recorder := httptest.NewRecorder()
+
+	// Act
+	err := Respond(recorder, http.StatusOK, make(chan int))
+
+	// Assert
+	var encodingError *EncodingError
+	assert.ErrorAs(t, err, &encodingError)
+	assert.False(t, recorder.Flushed)
+	assert.Empty(t, recorder.Body.String())
+	assert.Empty(t, recorder.Header().Get("Content-Type"))
+}
+
+func TestNegotiate_PassesInjectOptions(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, &bakery{}, Self("/api/v1/bakeries/{slug}", "get itself"))
+
+	var respondErr error
+
+	handler := Negotiate(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		respondErr = Respond(writer, http.StatusOK, &bakery{ID: 5})
+	}), UsingRegistry(registry), WithInjectOptions(OnUnresolvedToken(FailOnUnresolvedTokens)))
+
+	recorder := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// Assert
+	assert.ErrorIs(t, respondErr, ErrUnresolvedToken)
+	assert.Empty(t, recorder.Body.String())
+}
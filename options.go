@@ -1,17 +1,52 @@
 package gohateoas
 
-import "net/http"
+import (
+	"net/http"
+	"reflect"
+)
 
-// LinkInfo represents a link to a resource.
+// LinkInfo represents a link to a resource. It can't be compared using == or used as a map key,
+// since it holds Params and the functions of options like When and Computed.
 type LinkInfo struct {
 	Method  string `json:"method"`
 	Href    string `json:"href"`
 	Comment string `json:"comment"`
+
+	// Type is an optional hint of the media type that is returned by the link
+	Type string `json:"type,omitempty"`
+
+	// Name optionally distinguishes links that share the same relation
+	Name string `json:"name,omitempty"`
+
+	// Deprecation optionally points to a url with information about the deprecation of the link
+	Deprecation string `json:"deprecation,omitempty"`
+
+	// Templated is set when the href still contains RFC 6570 expressions, because none of their
+	// variables had a value. Clients are expected to expand these themselves.
+	Templated bool `json:"templated,omitempty"`
+
+	// Rel optionally overrides the relation of the link, allowing multiple links to share
+	// a relation in formats like HAL. If empty, the registered action is used.
+	Rel string `json:"-"`
+
+	// Params optionally holds additional target attributes like hreflang, or extension parameters.
+	// They are used when the link is rendered as an RFC 8288 Link header.
+	Params map[string]string `json:"-"`
+
+	// RequestBody optionally holds an example of the request body the link expects, like a struct
+	// with json tags. Formats like Siren use it to describe the fields of an action.
+	RequestBody any `json:"-"`
+
+	// condition decides whether the link applies to an object, set using When
+	condition func(object any) bool
+
+	// compute replaces the link with one that is computed using the object, set using options like SelfFunc
+	compute func(object any) (LinkInfo, bool)
 }
 
 // LinkOption is used to register links in a LinkRegistry. Urls may contain
-// replaceable tokens like {id} or {name}. These tokens will be replaced by
-// the values of the corresponding json fields in the struct.
+// RFC 6570 URI templates like {id} or {?page,size}. These expressions will be expanded
+// using the values of the corresponding json fields in the struct.
 type LinkOption func(map[string]LinkInfo)
 
 // Custom allows you to define a custom action and info. Urls may contain
@@ -23,6 +58,95 @@ func Custom(action string, info LinkInfo) LinkOption {
 	}
 }
 
+// Computed allows you to define a custom action of which the link is computed using the object when links
+// are injected, like a signed download url or an href built by your router. The link is left out if the
+// function returns false. The href is used as it is, it is not expanded like a template. The function
+// always receives the object as a value, even if a pointer to it was passed to InjectLinks.
+func Computed(action string, compute func(object any) (LinkInfo, bool)) LinkOption {
+	return computedLink(action, LinkInfo{}, func(object any) (LinkInfo, bool) {
+		value := ensureConcrete(reflect.ValueOf(object))
+		if !value.IsValid() {
+			return LinkInfo{}, false
+		}
+
+		return compute(value.Interface())
+	})
+}
+
+// WithRequestBody sets the expected request body on the links of the given options, formats like
+// Siren use it to describe the fields of an action. The body is usually an empty struct with json tags.
+func WithRequestBody(body any, options ...LinkOption) LinkOption {
+	return func(registry map[string]LinkInfo) {
+		links := make(map[string]LinkInfo)
+		for _, option := range options {
+			option(links)
+		}
+
+		for action, linkInfo := range links {
+			linkInfo.RequestBody = body
+			registry[action] = linkInfo
+		}
+	}
+}
+
+// When only adds the links of the given options to objects for which the predicate returns true, like
+// a publish link on drafts. The predicate receives the object as T, which may be a value or a pointer.
+// Objects that can't be converted to T don't get the links.
+func When[T any](predicate func(object T) bool, options ...LinkOption) LinkOption {
+	condition := func(object any) bool {
+		typedObject, ok := objectAs[T](object)
+
+		return ok && predicate(typedObject)
+	}
+
+	return func(registry map[string]LinkInfo) {
+		links := make(map[string]LinkInfo)
+		for _, option := range options {
+			option(links)
+		}
+
+		for action, linkInfo := range links {
+			// Nested conditions must all be met
+			if previous := linkInfo.condition; previous != nil {
+				linkInfo.condition = func(object any) bool {
+					return previous(object) && condition(object)
+				}
+			} else {
+				linkInfo.condition = condition
+			}
+
+			registry[action] = linkInfo
+		}
+	}
+}
+
+// objectAs converts the object to T, dereferencing it or taking its address if necessary
+func objectAs[T any](object any) (T, bool) {
+	if typedObject, ok := object.(T); ok {
+		return typedObject, true
+	}
+
+	var zero T
+
+	value := ensureConcrete(reflect.ValueOf(object))
+	if !value.IsValid() {
+		return zero, false
+	}
+
+	if typedObject, ok := value.Interface().(T); ok {
+		return typedObject, true
+	}
+
+	pointer := reflect.New(value.Type())
+	pointer.Elem().Set(value)
+
+	if typedObject, ok := pointer.Interface().(T); ok {
+		return typedObject, true
+	}
+
+	return zero, false
+}
+
 // Self Adds the self url of an object to the type, probably an url with an id. Urls may contain
 // replaceable tokens like {id} or {name}. These tokens will be replaced by
 // the values of the corresponding json fields in the struct.
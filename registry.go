@@ -1,43 +1,435 @@
 package gohateoas
 
 import (
-	"fmt"
+	"encoding/json"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/survivorbat/go-tsyncmap"
 )
 
 // DefaultLinkRegistry is the global registry for hateoas links
 var DefaultLinkRegistry = NewLinkRegistry()
 
-// replacer is used to strip stars and arrays from the %T output
-var replacer = strings.NewReplacer("[", "", "]", "", "*", "")
+// typeKeyOf returns the type the links of the object are registered on, which is the type of the
+// object without pointers, slices and arrays. This way a []*Cupcake gets the links of Cupcake.
+func typeKeyOf(object any) reflect.Type {
+	return elementTypeOf(reflect.TypeOf(object))
+}
 
-// typeNameOf returns the %T and also strips any stars and arrays from it, used internally for
-// HATEOAS.
+// typeNameOf returns the name of the type the links of the object are registered on, including
+// its package, like gohateoas.Cupcake. It is only used in messages, since it is not unique.
 func typeNameOf(object any) string {
-	return replacer.Replace(fmt.Sprintf("%T", object))
+	typeInfo := typeKeyOf(object)
+	if typeInfo == nil {
+		return "nil"
+	}
+
+	return typeInfo.String()
+}
+
+// shortTypeNameOf returns the name of the type of the object without its package and type parameters,
+// like cupcake for a []*gohateoas.cupcake or page for a gohateoas.page[gohateoas.cupcake].
+func shortTypeNameOf(object any) string {
+	return shortNameOfType(typeKeyOf(object))
+}
+
+// shortNameOfType returns the name of the type without its package and type parameters, see shortTypeNameOf
+func shortNameOfType(typeInfo reflect.Type) string {
+	if typeInfo == nil {
+		return "nil"
+	}
+
+	// Types without a name, like map[string]int, don't have anything to shorten
+	name := typeInfo.Name()
+	if name == "" {
+		return typeInfo.String()
+	}
+
+	name, _, _ = strings.Cut(name, "[")
+
+	return name
+}
+
+// RegistryOption is used to configure a LinkRegistry
+type RegistryOption func(registry *LinkRegistry)
+
+// RejectDuplicateLinks makes AddLinksOn and TryRegisterOn return a DuplicateLinkError if an action is
+// added to a type that already has a link with that action, or if it is added twice in the same call.
+func RejectDuplicateLinks() RegistryOption {
+	return func(registry *LinkRegistry) {
+		registry.rejectDuplicateLinks = true
+	}
+}
+
+// WithRegistryBaseURL makes every injection using the registry prefix relative hrefs with the base url, like
+// WithBaseURL does. Passing WithBaseURL when injecting links overrides it.
+func WithRegistryBaseURL(baseURL string) RegistryOption {
+	return func(registry *LinkRegistry) {
+		registry.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
 }
 
 // NewLinkRegistry instantiates a new LinkRegistry, only used for testing or when overriding
 // the DefaultLinkRegistry.
-func NewLinkRegistry() LinkRegistry {
-	return make(LinkRegistry)
+func NewLinkRegistry(options ...RegistryOption) *LinkRegistry {
+	registry := &LinkRegistry{}
+	for _, option := range options {
+		option(registry)
+	}
+
+	return registry
+}
+
+// LinkRegistry allows you to register URLs on objects, populating links in responses. It is safe to
+// register links while others are being injected, injection works on a snapshot of the registry
+// and does not have to wait for registrations.
+type LinkRegistry struct {
+	// mutex makes sure only one registration at a time copies the links
+	mutex sync.Mutex
+
+	// links holds a map[reflect.Type]map[string]LinkInfo, it is never modified but replaced by an updated copy
+	links atomic.Value
+
+	// fields caches the json fields of struct types, see fieldsOf
+	fields tsyncmap.Map[reflect.Type, map[string]string]
+
+	// rejectDuplicateLinks is set using RejectDuplicateLinks
+	rejectDuplicateLinks bool
+
+	// baseURL is set using WithRegistryBaseURL
+	baseURL string
+}
+
+// withoutLinks returns a new registry with the same options, but without any links
+func (l *LinkRegistry) withoutLinks() *LinkRegistry {
+	return &LinkRegistry{rejectDuplicateLinks: l.rejectDuplicateLinks, baseURL: l.baseURL}
+}
+
+// snapshot returns the current links of every type, the result must not be modified
+func (l *LinkRegistry) snapshot() map[reflect.Type]map[string]LinkInfo {
+	if l == nil {
+		return nil
+	}
+
+	links, _ := l.links.Load().(map[reflect.Type]map[string]LinkInfo)
+
+	return links
 }
 
-// LinkRegistry allows you to register URLs on objects, populating links in responses.
-type LinkRegistry map[string]map[string]LinkInfo
+// update replaces the links with a copy that was modified by the given function
+func (l *LinkRegistry) update(modify func(links map[reflect.Type]map[string]LinkInfo)) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	current := l.snapshot()
+
+	updated := make(map[reflect.Type]map[string]LinkInfo, len(current)+1)
+	for name, links := range current {
+		updated[name] = links
+	}
+
+	modify(updated)
+
+	l.links.Store(updated)
+}
 
-// Register registers links to an object using the DefaultLinkRegistry.
+// Links returns a copy of the links registered on the type of the object, or nil if it is not registered
+func (l *LinkRegistry) Links(object any) map[string]LinkInfo {
+	links, ok := l.snapshot()[typeKeyOf(object)]
+	if !ok {
+		return nil
+	}
+
+	result := make(map[string]LinkInfo, len(links))
+	for action, linkInfo := range links {
+		result[action] = linkInfo
+	}
+
+	return result
+}
+
+// Register registers links to an object using the DefaultLinkRegistry, replacing the links that were
+// registered on it before. Use AddLinks to keep them.
 func Register(object any, options ...LinkOption) {
 	RegisterOn(DefaultLinkRegistry, object, options...)
 }
 
-// RegisterOn registers links to an object in the given registry.
-func RegisterOn(linkRegistry LinkRegistry, object any, options ...LinkOption) {
+// RegisterOn registers links to an object in the given registry, replacing the links that were
+// registered on it before. Use AddLinksOn to keep them.
+func RegisterOn(linkRegistry *LinkRegistry, object any, options ...LinkOption) {
 	links := make(map[string]LinkInfo)
 	for _, option := range options {
 		option(links)
 	}
 
-	name := typeNameOf(object)
-	linkRegistry[name] = links
+	typeInfo := typeKeyOf(object)
+
+	linkRegistry.update(func(registered map[reflect.Type]map[string]LinkInfo) {
+		registered[typeInfo] = links
+	})
+}
+
+// MustRegister is similar to Register, but panics if the links are invalid. See TryRegisterOn.
+func MustRegister(object any, options ...LinkOption) {
+	MustRegisterOn(DefaultLinkRegistry, object, options...)
+}
+
+// MustRegisterOn is similar to RegisterOn, but panics if the links are invalid. See TryRegisterOn.
+func MustRegisterOn(linkRegistry *LinkRegistry, object any, options ...LinkOption) {
+	if err := TryRegisterOn(linkRegistry, object, options...); err != nil {
+		panic(err)
+	}
+}
+
+// TryRegisterOn is similar to RegisterOn, but validates every token in the hrefs against the json fields
+// of the object first. It returns an UnresolvedTokenError for the first token that does not match a
+// json field, or ErrNotAStruct if the object is not a struct. Nothing is registered if an error is returned.
+func TryRegisterOn(linkRegistry *LinkRegistry, object any, options ...LinkOption) error {
+	links, err := linkRegistry.collectLinks(object, options)
+	if err != nil {
+		return err
+	}
+
+	if err = linkRegistry.validateLinks(object, links); err != nil {
+		return err
+	}
+
+	typeInfo := typeKeyOf(object)
+
+	linkRegistry.update(func(registered map[reflect.Type]map[string]LinkInfo) {
+		registered[typeInfo] = links
+	})
+
+	return nil
+}
+
+// AddLinks adds links to an object using the DefaultLinkRegistry, keeping the links that are already
+// registered on it. See AddLinksOn.
+func AddLinks(object any, options ...LinkOption) error {
+	return AddLinksOn(DefaultLinkRegistry, object, options...)
+}
+
+// AddLinksOn adds links to an object in the given registry, keeping the links that are already registered
+// on it. This allows several packages to register links on the same type. Links with an action that is
+// already registered replace the existing link, unless the registry was created with RejectDuplicateLinks.
+// In that case a DuplicateLinkError is returned and nothing is added.
+func AddLinksOn(linkRegistry *LinkRegistry, object any, options ...LinkOption) error {
+	links, err := linkRegistry.collectLinks(object, options)
+	if err != nil {
+		return err
+	}
+
+	typeInfo := typeKeyOf(object)
+
+	linkRegistry.update(func(registered map[reflect.Type]map[string]LinkInfo) {
+		existing := registered[typeInfo]
+
+		if linkRegistry.rejectDuplicateLinks {
+			for action := range links {
+				if _, ok := existing[action]; ok {
+					err = &DuplicateLinkError{Type: typeNameOf(object), Action: action}
+
+					return
+				}
+			}
+		}
+
+		// The links of a type are shared with snapshots, so they're copied instead of modified
+		merged := make(map[string]LinkInfo, len(existing)+len(links))
+		for action, linkInfo := range existing {
+			merged[action] = linkInfo
+		}
+
+		for action, linkInfo := range links {
+			merged[action] = linkInfo
+		}
+
+		registered[typeInfo] = merged
+	})
+
+	return err
+}
+
+// Unregister removes all links registered on the type of the object
+func (l *LinkRegistry) Unregister(object any) {
+	typeInfo := typeKeyOf(object)
+
+	l.update(func(registered map[reflect.Type]map[string]LinkInfo) {
+		delete(registered, typeInfo)
+	})
+}
+
+// RemoveLink removes the link registered under the action from the type of the object, the type
+// stays registered even if it has no links left.
+func (l *LinkRegistry) RemoveLink(object any, action string) {
+	typeInfo := typeKeyOf(object)
+
+	l.update(func(registered map[reflect.Type]map[string]LinkInfo) {
+		links, ok := registered[typeInfo]
+		if !ok {
+			return
+		}
+
+		// The links of a type are shared with snapshots, so they're copied instead of modified
+		remaining := make(map[string]LinkInfo, len(links))
+		for existingAction, linkInfo := range links {
+			if existingAction != action {
+				remaining[existingAction] = linkInfo
+			}
+		}
+
+		registered[typeInfo] = remaining
+	})
+}
+
+// collectLinks applies the options, if the registry rejects duplicate links a DuplicateLinkError is
+// returned for an action that is added by more than one option.
+func (l *LinkRegistry) collectLinks(object any, options []LinkOption) (map[string]LinkInfo, error) {
+	links := make(map[string]LinkInfo)
+
+	for _, option := range options {
+		if !l.rejectDuplicateLinks {
+			option(links)
+
+			continue
+		}
+
+		optionLinks := make(map[string]LinkInfo)
+		option(optionLinks)
+
+		for action, linkInfo := range optionLinks {
+			if _, ok := links[action]; ok {
+				return nil, &DuplicateLinkError{Type: typeNameOf(object), Action: action}
+			}
+
+			links[action] = linkInfo
+		}
+	}
+
+	return links, nil
+}
+
+// jsonMarshalerType is used to check if a type decides on its own json fields
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// validateLinks checks whether every required template variable in the hrefs matches a json field of the
+// object, dotted paths like bakery.id are followed into nested objects. Variables of optional expressions,
+// like {?page,size}, may be left for the client to expand.
+func (l *LinkRegistry) validateLinks(object any, links map[string]LinkInfo) error {
+	typeInfo := elementTypeOf(reflect.TypeOf(object))
+	if typeInfo == nil || typeInfo.Kind() != reflect.Struct {
+		return ErrNotAStruct
+	}
+
+	for action, linkInfo := range links {
+		for _, name := range requiredTemplateVariables(linkInfo.Href) {
+			if !l.jsonPathExists(typeInfo, name) {
+				return &UnresolvedTokenError{Type: typeNameOf(object), Action: action, Href: linkInfo.Href, Token: name}
+			}
+		}
+	}
+
+	return nil
+}
+
+// jsonPathExists returns true if the dotted json path, like bakery.id or owners.0.id, can be present in
+// the json of the type. Keys that contain dots themselves take precedence over paths, like in lookupPath.
+// Paths that refer to an enclosing object can't be checked and are assumed to exist, as long as they start
+// with $parent, parent, ctx or the name of a registered type that is not a field, like bakery.id.
+func (l *LinkRegistry) jsonPathExists(typeInfo reflect.Type, path string) bool {
+	typeInfo = ensureConcrete(typeInfo)
+
+	if typeInfo.Kind() == reflect.Struct && !isJsonMarshaler(typeInfo) {
+		fields := l.fieldsOf(typeInfo)
+
+		if _, ok := fields[path]; ok {
+			return true
+		}
+
+		if scopeName, _, ok := strings.Cut(path, "."); ok {
+			if _, isField := fields[scopeName]; !isField {
+				return l.isScopeName(scopeName)
+			}
+		}
+	}
+
+	for _, segment := range strings.Split(path, ".") {
+		typeInfo = ensureConcrete(typeInfo)
+
+		//nolint:exhaustive // Other kinds don't have nested values
+		switch typeInfo.Kind() {
+		case reflect.Struct:
+			// We can't know what keys a custom MarshalJSON produces
+			if isJsonMarshaler(typeInfo) {
+				return true
+			}
+
+			fieldName, ok := l.fieldsOf(typeInfo)[segment]
+			if !ok {
+				return false
+			}
+
+			field, _ := typeInfo.FieldByName(fieldName)
+			typeInfo = field.Type
+
+		case reflect.Slice, reflect.Array:
+			if _, err := strconv.Atoi(segment); err != nil {
+				return false
+			}
+
+			typeInfo = typeInfo.Elem()
+
+		case reflect.Map:
+			typeInfo = typeInfo.Elem()
+
+		case reflect.Interface:
+			return true
+
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// isScopeName returns true if the name refers to an enclosing object or the context, like $parent, ctx or
+// the name of a registered type
+func (l *LinkRegistry) isScopeName(name string) bool {
+	if name == parentScope || name == "parent" || name+"." == variablePrefix {
+		return true
+	}
+
+	for typeInfo := range l.snapshot() {
+		if strings.EqualFold(shortNameOfType(typeInfo), name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isJsonMarshaler returns true if the type or a pointer to it implements json.Marshaler
+func isJsonMarshaler(typeInfo reflect.Type) bool {
+	return typeInfo.Implements(jsonMarshalerType) || reflect.PointerTo(typeInfo).Implements(jsonMarshalerType)
+}
+
+// elementTypeOf strips pointers, slices and arrays from the type
+func elementTypeOf(typeInfo reflect.Type) reflect.Type {
+	if typeInfo == nil {
+		return nil
+	}
+
+	//nolint:exhaustive // Only these kinds have an element type we care about
+	switch typeInfo.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return elementTypeOf(typeInfo.Elem())
+
+	default:
+		return typeInfo
+	}
 }
@@ -1,9 +1,14 @@
 package gohateoas
 
 import (
+	"fmt"
 	"net/http"
+	"reflect"
+	"sync"
 	"testing"
 
+	adminapi "github.com/ing-bank/gohateoas/internal/fixtures/admin/api"
+	shopapi "github.com/ing-bank/gohateoas/internal/fixtures/shop/api"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -31,19 +36,53 @@ func TestGetTypeName_ReturnsNameOnTypeB(t *testing.T) {
 	assert.Equal(t, "gohateoas.testTypeB", result)
 }
 
+func TestGetTypeName_ReturnsNameOfGenericType(t *testing.T) {
+	t.Parallel()
+	// Act
+	result := typeNameOf([]*page[cupcake]{})
+
+	// Assert
+	assert.Equal(t, "gohateoas.page[github.com/ing-bank/gohateoas.cupcake]", result)
+}
+
+func TestShortTypeNameOf_ReturnsNameWithoutPackage(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		object   any
+		expected string
+	}{
+		"struct":     {object: cupcake{}, expected: "cupcake"},
+		"pointers":   {object: []*bakery{}, expected: "bakery"},
+		"generic":    {object: page[cupcake]{}, expected: "page"},
+		"other pkg":  {object: shopapi.User{}, expected: "User"},
+		"unnamed":    {object: map[string]int{}, expected: "map[string]int"},
+		"nil object": {object: nil, expected: "nil"},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Act
+			result := shortTypeNameOf(testData.object)
+
+			// Assert
+			assert.Equal(t, testData.expected, result)
+		})
+	}
+}
+
 type TestRegisterOnType struct{}
 
 func TestRegisterOn_RegistersExpectedLinks(t *testing.T) {
 	t.Parallel()
 	tests := map[string]struct {
 		options  []LinkOption
-		expected LinkRegistry
+		expected map[string]LinkInfo
 	}{
 		"no options": {
-			options: []LinkOption{},
-			expected: map[string]map[string]LinkInfo{
-				"gohateoas.TestRegisterOnType": {},
-			},
+			options:  []LinkOption{},
+			expected: map[string]LinkInfo{},
 		},
 		"all options": {
 			options: []LinkOption{
@@ -55,16 +94,14 @@ func TestRegisterOn_RegistersExpectedLinks(t *testing.T) {
 				Delete("/cupcakes", "Delete a cupcake"),
 				Custom("custom", LinkInfo{Method: http.MethodConnect, Href: "/cupcakes/custom", Comment: "Custom action"}),
 			},
-			expected: map[string]map[string]LinkInfo{
-				"gohateoas.TestRegisterOnType": {
-					"self":   LinkInfo{Method: http.MethodGet, Href: "/cupcakes/{id}", Comment: "Get a single cupcake"},
-					"index":  LinkInfo{Method: http.MethodGet, Href: "/cupcakes", Comment: "Get all cupcakes"},
-					"post":   LinkInfo{Method: http.MethodPost, Href: "/cupcakes", Comment: "Create a new cupcake"},
-					"put":    LinkInfo{Method: http.MethodPut, Href: "/cupcakes/{id}", Comment: "Fully update a cupcake"},
-					"patch":  LinkInfo{Method: http.MethodPatch, Href: "/cupcakes/{id}", Comment: "Partially update a cupcake"},
-					"delete": LinkInfo{Method: http.MethodDelete, Href: "/cupcakes", Comment: "Delete a cupcake"},
-					"custom": LinkInfo{Method: http.MethodConnect, Href: "/cupcakes/custom", Comment: "Custom action"},
-				},
+			expected: map[string]LinkInfo{
+				"self":   {Method: http.MethodGet, Href: "/cupcakes/{id}", Comment: "Get a single cupcake"},
+				"index":  {Method: http.MethodGet, Href: "/cupcakes", Comment: "Get all cupcakes"},
+				"post":   {Method: http.MethodPost, Href: "/cupcakes", Comment: "Create a new cupcake"},
+				"put":    {Method: http.MethodPut, Href: "/cupcakes/{id}", Comment: "Fully update a cupcake"},
+				"patch":  {Method: http.MethodPatch, Href: "/cupcakes/{id}", Comment: "Partially update a cupcake"},
+				"delete": {Method: http.MethodDelete, Href: "/cupcakes", Comment: "Delete a cupcake"},
+				"custom": {Method: http.MethodConnect, Href: "/cupcakes/custom", Comment: "Custom action"},
 			},
 		},
 	}
@@ -80,7 +117,7 @@ func TestRegisterOn_RegistersExpectedLinks(t *testing.T) {
 			RegisterOn(registry, TestRegisterOnType{}, testData.options...)
 
 			// Assert
-			assert.Equal(t, testData.expected, registry)
+			assert.Equal(t, testData.expected, registry.Links(TestRegisterOnType{}))
 		})
 	}
 }
@@ -94,5 +131,630 @@ func TestRegister_UsesDefaultRegistry(t *testing.T) {
 	Register(TestRegisterType{}, Self("test", "get it"))
 
 	// Assert
-	assert.NotEmpty(t, DefaultLinkRegistry["gohateoas.TestRegisterType"])
+	assert.NotEmpty(t, DefaultLinkRegistry.Links(TestRegisterType{}))
+}
+
+func TestWithRequestBody_SetsBodyOnLinks(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	type requestBody struct {
+		Name string `json:"name"`
+	}
+
+	registry := NewLinkRegistry()
+
+	// Act
+	RegisterOn(registry, TestRegisterOnType{},
+		Self("/cupcakes/{id}", "Get a single cupcake"),
+		WithRequestBody(requestBody{}, Post("/cupcakes", "Create a new cupcake"), Put("/cupcakes/{id}", "Fully update a cupcake")))
+
+	// Assert
+	expected := map[string]LinkInfo{
+		"self": {Method: http.MethodGet, Href: "/cupcakes/{id}", Comment: "Get a single cupcake"},
+		"post": {Method: http.MethodPost, Href: "/cupcakes", Comment: "Create a new cupcake", RequestBody: requestBody{}},
+		"put":  {Method: http.MethodPut, Href: "/cupcakes/{id}", Comment: "Fully update a cupcake", RequestBody: requestBody{}},
+	}
+
+	assert.Equal(t, expected, registry.Links(TestRegisterOnType{}))
+}
+
+type validatedBase struct {
+	ID int `json:"id"`
+}
+
+type validatedType struct {
+	validatedBase
+
+	Name     string `json:"name,omitempty"`
+	Untagged string
+	Ignored  string `json:"-"`
+}
+
+type customMarshalType struct{}
+
+func (customMarshalType) MarshalJSON() ([]byte, error) {
+	return []byte(`{"anything":1}`), nil
+}
+
+func TestTryRegisterOn_ValidatesTokens(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		object      any
+		options     []LinkOption
+		expectedErr error
+	}{
+		"valid tokens": {
+			object: &validatedType{},
+			options: []LinkOption{
+				Self("/things/{id}", ""),
+				Custom("named", LinkInfo{Href: "/things/{name}/{Untagged}"}),
+			},
+		},
+		"slice of type": {
+			object:  []*validatedType{},
+			options: []LinkOption{Self("/things/{id}", "")},
+		},
+		"no tokens": {
+			object:  validatedType{},
+			options: []LinkOption{Index("/things", "")},
+		},
+		"custom marshaler": {
+			object:  customMarshalType{},
+			options: []LinkOption{Self("/things/{anything}", "")},
+		},
+		"wrong case": {
+			object:      &validatedType{},
+			options:     []LinkOption{Self("/things/{ID}", "")},
+			expectedErr: &UnresolvedTokenError{Type: "gohateoas.validatedType", Action: "self", Href: "/things/{ID}", Token: "ID"},
+		},
+		"ignored field": {
+			object:      &validatedType{},
+			options:     []LinkOption{Delete("/things/{Ignored}", "")},
+			expectedErr: &UnresolvedTokenError{Type: "gohateoas.validatedType", Action: "delete", Href: "/things/{Ignored}", Token: "Ignored"},
+		},
+		"dotted path": {
+			object: &cupcake{},
+			options: []LinkOption{
+				Self("/bakeries/{bakery.id}/cupcakes/{id}", ""),
+				Custom("first", LinkInfo{Href: "/cupcakes/{bakery.cupcakes.0.id}"}),
+			},
+		},
+		"parent scope": {
+			object: &cupcake{},
+			options: []LinkOption{
+				Self("/bakeries/{$parent.id}/cupcakes/{id}", ""),
+				Custom("owner", LinkInfo{Href: "/owners/{parent.$parent.id}"}),
+			},
+		},
+		"context variable": {
+			object:  &cupcake{},
+			options: []LinkOption{Self("/{ctx.tenant}/cupcakes/{id}", "")},
+		},
+		"unknown scope": {
+			object:      &cupcake{},
+			options:     []LinkOption{Self("/bakeries/{bakry.id}", "")},
+			expectedErr: &UnresolvedTokenError{Type: "gohateoas.cupcake", Action: "self", Href: "/bakeries/{bakry.id}", Token: "bakry.id"},
+		},
+		"dotted path to unknown field": {
+			object:      &cupcake{},
+			options:     []LinkOption{Self("/bakeries/{bakery.name}", "")},
+			expectedErr: &UnresolvedTokenError{Type: "gohateoas.cupcake", Action: "self", Href: "/bakeries/{bakery.name}", Token: "bakery.name"},
+		},
+		"dotted path into scalar": {
+			object:      &cupcake{},
+			options:     []LinkOption{Self("/cupcakes/{name.first}", "")},
+			expectedErr: &UnresolvedTokenError{Type: "gohateoas.cupcake", Action: "self", Href: "/cupcakes/{name.first}", Token: "name.first"},
+		},
+		"index on slice without number": {
+			object:      &bakery{},
+			options:     []LinkOption{Self("/cupcakes/{cupcakes.first.id}", "")},
+			expectedErr: &UnresolvedTokenError{Type: "gohateoas.bakery", Action: "self", Href: "/cupcakes/{cupcakes.first.id}", Token: "cupcakes.first.id"},
+		},
+		"optional expressions": {
+			object:  &validatedType{},
+			options: []LinkOption{Index("/things{?page,size}{&sort}", "")},
+		},
+		"reserved expansion": {
+			object:      &validatedType{},
+			options:     []LinkOption{Self("{+pathh}/x", "")},
+			expectedErr: &UnresolvedTokenError{Type: "gohateoas.validatedType", Action: "self", Href: "{+pathh}/x", Token: "pathh"},
+		},
+		"fragment expansion": {
+			object:      &validatedType{},
+			options:     []LinkOption{Self("/things{#sectoin}", "")},
+			expectedErr: &UnresolvedTokenError{Type: "gohateoas.validatedType", Action: "self", Href: "/things{#sectoin}", Token: "sectoin"},
+		},
+		"required variable next to optional expression": {
+			object:      &validatedType{},
+			options:     []LinkOption{Index("/things/{page}{?size}", "")},
+			expectedErr: &UnresolvedTokenError{Type: "gohateoas.validatedType", Action: "index", Href: "/things/{page}{?size}", Token: "page"},
+		},
+		"not a struct": {
+			object:      "test",
+			options:     []LinkOption{Self("/things", "")},
+			expectedErr: ErrNotAStruct,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+
+			// Act
+			err := TryRegisterOn(registry, testData.object, testData.options...)
+
+			// Assert
+			assert.Equal(t, testData.expectedErr, err)
+
+			if testData.expectedErr != nil {
+				assert.Nil(t, registry.Links(testData.object))
+
+				return
+			}
+
+			assert.Len(t, registry.Links(testData.object), len(testData.options))
+		})
+	}
+}
+
+func TestTryRegisterOn_AcceptsRegisteredTypesAsScope(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, cheeseStore{}, Self("/stores/{id}", ""))
+
+	// Act
+	err := TryRegisterOn(registry, cheese{}, Self("/stores/{cheesestore.id}/cheeses/{id}", ""))
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestMustRegisterOn_PanicsOnInvalidToken(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+
+	// Act
+	result := func() { MustRegisterOn(registry, &validatedType{}, Self("/things/{ID}", "")) }
+
+	// Assert
+	assert.PanicsWithError(t, `unresolved token: {ID} in self link "/things/{ID}" of gohateoas.validatedType`, result)
+}
+
+func TestMustRegister_UsesDefaultRegistry(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	type TestMustRegisterType struct {
+		ID int `json:"id"`
+	}
+
+	// Act
+	MustRegister(TestMustRegisterType{}, Self("/things/{id}", "get it"))
+
+	// Assert
+	assert.NotEmpty(t, DefaultLinkRegistry.Links(TestMustRegisterType{}))
+}
+
+func TestComputed_ComputesLinkPerObject(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+
+	var received []any
+
+	RegisterOn(registry, cupcake{},
+		Self("/cupcakes/{id}", ""),
+		Computed("download", func(object any) (LinkInfo, bool) {
+			received = append(received, object)
+
+			cake, _ := object.(cupcake)
+			if cake.Name == "" {
+				return LinkInfo{}, false
+			}
+
+			return LinkInfo{Method: http.MethodGet, Href: fmt.Sprintf("/files/%s?signature=%d", cake.Name, cake.ID*7), Type: "image/png"}, true
+		}))
+
+	object := []cupcake{{ID: 1, Name: "{name}"}, {ID: 2}}
+
+	// Act
+	result := InjectLinks(registry, object)
+
+	// Assert
+	expected := `[
+		{"id":1,"name":"{name}","bakery":null,"_links":{
+			"self":{"method":"GET","href":"/cupcakes/1","comment":""},
+			"download":{"method":"GET","href":"/files/{name}?signature=7","comment":"","type":"image/png"}
+		}},
+		{"id":2,"name":"","bakery":null,"_links":{
+			"self":{"method":"GET","href":"/cupcakes/2","comment":""}
+		}}
+	]`
+	assert.JSONEq(t, expected, string(result))
+	assert.Equal(t, []any{object[0], object[1]}, received)
+}
+
+func TestComputed_ReceivesValueOfPointer(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+
+	RegisterOn(registry, cupcake{}, Computed("download", func(object any) (LinkInfo, bool) {
+		cake, ok := object.(cupcake)
+
+		return LinkInfo{Method: http.MethodGet, Href: fmt.Sprintf("/files/%d", cake.ID)}, ok
+	}))
+
+	// Act
+	result := InjectLinks(registry, &cupcake{ID: 3})
+
+	// Assert
+	assert.JSONEq(t, `{"id":3,"name":"","bakery":null,"_links":{"download":{"method":"GET","href":"/files/3","comment":""}}}`, string(result))
+}
+
+func TestLinkRegistry_Links_ReturnsCopy(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, cupcake{}, Self("/cupcakes/{id}", ""))
+
+	// Act
+	registry.Links(cupcake{})["delete"] = LinkInfo{Href: "/cupcakes/{id}"}
+
+	// Assert
+	assert.Len(t, registry.Links(cupcake{}), 1)
+}
+
+func TestLinkRegistry_ZeroValueIsUsable(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := &LinkRegistry{}
+
+	// Act
+	RegisterOn(registry, bakery{}, Self("/bakeries/{id}", ""))
+
+	// Assert
+	assert.JSONEq(t, `{"id":1,"_links":{"self":{"method":"GET","href":"/bakeries/1","comment":""}}}`, string(InjectLinks(registry, bakery{ID: 1})))
+}
+
+// TestLinkRegistry_IsSafeForConcurrentUse is meant to be run with -race
+func TestLinkRegistry_IsSafeForConcurrentUse(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, bakery{}, Self("/bakeries/{id}", ""))
+
+	var waitGroup sync.WaitGroup
+
+	// Act
+	for i := 0; i < 50; i++ {
+		waitGroup.Add(2)
+
+		go func(i int) {
+			defer waitGroup.Done()
+
+			RegisterOn(registry, cupcake{}, Self(fmt.Sprintf("/cupcakes/%d/{id}", i), ""))
+			_ = TryRegisterOn(registry, &bakery{}, Self("/bakeries/{id}", ""), Index("/bakeries", ""))
+		}(i)
+
+		go func() {
+			defer waitGroup.Done()
+
+			result := InjectLinks(registry, &bakery{ID: 1, Cupcakes: []*cupcake{{ID: 2}}})
+			_ = LinkHeader(registry, bakery{ID: 1})
+			_ = registry.Links(cupcake{})
+
+			// Assert
+			assert.Contains(t, string(result), `"href":"/bakeries/1"`)
+		}()
+	}
+
+	waitGroup.Wait()
+
+	// Assert
+	assert.Len(t, registry.Links(bakery{}), 2)
+	assert.Len(t, registry.Links(cupcake{}), 1)
+}
+
+// page is a generic type, its instantiations are different types
+type page[T any] struct {
+	Items []T `json:"items"`
+	Next  int `json:"next"`
+}
+
+func TestRegisterOn_DoesNotMixUpTypesWithTheSameName(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, shopapi.User{}, Self("/shop/users/{id}", ""))
+	RegisterOn(registry, adminapi.User{}, Self("/admin/users/{name}", ""))
+
+	// Act
+	shopResult := InjectLinks(registry, []*shopapi.User{{ID: 1}})
+	adminResult := InjectLinks(registry, &adminapi.User{Name: "root"})
+
+	// Assert
+	assert.JSONEq(t, `[{"id":1,"_links":{"self":{"method":"GET","href":"/shop/users/1","comment":""}}}]`, string(shopResult))
+	assert.JSONEq(t, `{"name":"root","_links":{"self":{"method":"GET","href":"/admin/users/root","comment":""}}}`, string(adminResult))
+}
+
+func TestRegisterOn_DoesNotMixUpGenericInstantiations(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, page[cupcake]{}, Self("/cupcakes?page={next}", ""))
+	RegisterOn(registry, page[bakery]{}, Self("/bakeries?page={next}", ""))
+	RegisterOn(registry, bakery{}, Self("/bakeries/{id}", ""))
+
+	// Act
+	cupcakeResult := InjectLinks(registry, page[cupcake]{Next: 2})
+	bakeryResult := InjectLinks(registry, &page[bakery]{Items: []bakery{{ID: 3}}, Next: 4})
+
+	// Assert
+	assert.JSONEq(t, `{"items":null,"next":2,"_links":{"self":{"method":"GET","href":"/cupcakes?page=2","comment":""}}}`, string(cupcakeResult))
+	assert.JSONEq(t, `{"items":[{"id":3,"_links":{"self":{"method":"GET","href":"/bakeries/3","comment":""}}}],"next":4,"_links":{"self":{"method":"GET","href":"/bakeries?page=4","comment":""}}}`, string(bakeryResult))
+	assert.Nil(t, registry.Links(page[string]{}))
+}
+
+func TestLinkRegistry_Links_IgnoresPointersSlicesAndArrays(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, &cupcake{}, Self("/cupcakes/{id}", ""))
+
+	// Act
+	results := []map[string]LinkInfo{
+		registry.Links(cupcake{}),
+		registry.Links([]*cupcake{}),
+		registry.Links([2][]cupcake{}),
+		registry.Links(map[string]cupcake{}),
+	}
+
+	// Assert
+	assert.NotNil(t, results[0])
+	assert.Equal(t, results[0], results[1])
+	assert.Equal(t, results[0], results[2])
+	assert.Nil(t, results[3])
+}
+
+// cachedTypesOf returns the types of which the json fields are cached in the registry
+func cachedTypesOf(registry *LinkRegistry) []reflect.Type {
+	var result []reflect.Type
+
+	registry.fields.Range(func(typeInfo reflect.Type, _ map[string]string) bool {
+		result = append(result, typeInfo)
+
+		return true
+	})
+
+	return result
+}
+
+func TestLinkRegistry_CachesFieldsPerRegistry(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, bakery{}, Self("/bakeries/{id}", ""))
+
+	otherRegistry := NewLinkRegistry()
+	RegisterOn(otherRegistry, bakery{}, Self("/bakeries/{id}", ""))
+
+	// Act
+	_ = InjectLinks(registry, bakery{ID: 1, Cupcakes: []*cupcake{{ID: 2}}})
+
+	// Assert
+	assert.Equal(t, []reflect.Type{reflect.TypeOf(bakery{})}, cachedTypesOf(registry))
+	assert.Empty(t, cachedTypesOf(otherRegistry))
+}
+
+func TestLinkRegistry_ClearFieldCache_RemovesCachedFields(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	_ = TryRegisterOn(registry, cupcake{}, Self("/bakeries/{bakery.id}/cupcakes/{id}", ""))
+
+	// Act
+	registry.ClearFieldCache()
+
+	// Assert
+	assert.Empty(t, cachedTypesOf(registry))
+	assert.JSONEq(t, `{"id":1,"name":"","bakery":{"id":2},"_links":{"self":{"method":"GET","href":"/bakeries/2/cupcakes/1","comment":""}}}`,
+		string(InjectLinks(registry, cupcake{ID: 1, Bakery: &bakery{ID: 2}})))
+}
+
+func TestLinkRegistry_CachesFieldsOfSameNamedTypesSeparately(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	type wrapper struct {
+		Shop  shopapi.User  `json:"shop"`
+		Admin adminapi.User `json:"admin"`
+	}
+
+	registry := NewLinkRegistry()
+
+	// Act
+	errs := []error{
+		TryRegisterOn(registry, wrapper{}, Self("/users/{shop.id}/{admin.name}", "")),
+		TryRegisterOn(registry, wrapper{}, Self("/users/{shop.name}", "")),
+		TryRegisterOn(registry, wrapper{}, Self("/users/{admin.id}", "")),
+	}
+
+	// Assert
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], ErrUnresolvedToken)
+	assert.ErrorIs(t, errs[2], ErrUnresolvedToken)
+}
+
+func TestInjectLinks_IgnoresNilRegistry(t *testing.T) {
+	t.Parallel()
+	// Act
+	result := InjectLinks(nil, bakery{ID: 1})
+
+	// Assert
+	assert.JSONEq(t, `{"id":1}`, string(result))
+}
+
+func TestAddLinksOn_KeepsExistingLinks(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, cupcake{}, Self("/cupcakes/{id}", ""), Index("/cupcakes", ""))
+
+	// Act
+	err := AddLinksOn(registry, cupcake{}, Post("/cupcakes", ""), Index("/v2/cupcakes", ""))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]LinkInfo{
+		"self":  {Method: http.MethodGet, Href: "/cupcakes/{id}"},
+		"index": {Method: http.MethodGet, Href: "/v2/cupcakes"},
+		"post":  {Method: http.MethodPost, Href: "/cupcakes"},
+	}, registry.Links(cupcake{}))
+}
+
+func TestAddLinksOn_RegistersNewType(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+
+	// Act
+	err := AddLinksOn(registry, []*cupcake{}, Self("/cupcakes/{id}", ""))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]LinkInfo{"self": {Method: http.MethodGet, Href: "/cupcakes/{id}"}}, registry.Links(cupcake{}))
+}
+
+func TestAddLinks_UsesDefaultRegistry(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	type addedLinksType struct{}
+
+	// Act
+	errs := []error{
+		AddLinks(addedLinksType{}, Self("/added", "")),
+		AddLinks(addedLinksType{}, Index("/added", "")),
+	}
+
+	// Assert
+	assert.Equal(t, []error{nil, nil}, errs)
+	assert.Len(t, DefaultLinkRegistry.Links(addedLinksType{}), 2)
+}
+
+func TestAddLinksFor_KeepsExistingLinks(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterFor(registry, Templates[cupcake](Self("/cupcakes/{id}", "")))
+
+	// Act
+	err := AddLinksFor(registry, Templates[cupcake](Index("/cupcakes", "")))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, registry.Links(cupcake{}), 2)
+}
+
+func TestAddLinksOn_RejectsDuplicateLinks(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		existing []LinkOption
+		options  []LinkOption
+	}{
+		"already registered": {
+			existing: []LinkOption{Self("/cupcakes/{id}", "")},
+			options:  []LinkOption{Index("/cupcakes", ""), Self("/v2/cupcakes/{id}", "")},
+		},
+		"added twice": {
+			options: []LinkOption{Self("/cupcakes/{id}", ""), Self("/v2/cupcakes/{id}", "")},
+		},
+		"added twice in one option": {
+			options: []LinkOption{Self("/cupcakes/{id}", ""), WithRequestBody(cupcake{}, Self("/v2/cupcakes/{id}", ""))},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry(RejectDuplicateLinks())
+			RegisterOn(registry, cupcake{}, testData.existing...)
+
+			before := registry.Links(cupcake{})
+
+			// Act
+			err := AddLinksOn(registry, cupcake{}, testData.options...)
+
+			// Assert
+			assert.ErrorIs(t, err, ErrDuplicateLink)
+			assert.Equal(t, &DuplicateLinkError{Type: "gohateoas.cupcake", Action: "self"}, err)
+			assert.Equal(t, before, registry.Links(cupcake{}))
+		})
+	}
+}
+
+func TestTryRegisterOn_RejectsDuplicateLinks(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry(RejectDuplicateLinks())
+
+	// Act
+	err := TryRegisterOn(registry, cupcake{}, Self("/cupcakes/{id}", ""), Self("/v2/cupcakes/{id}", ""))
+
+	// Assert
+	assert.ErrorIs(t, err, ErrDuplicateLink)
+	assert.Nil(t, registry.Links(cupcake{}))
+}
+
+func TestLinkRegistry_Clone_KeepsOptions(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry(RejectDuplicateLinks())
+	RegisterOn(registry, cupcake{}, Self("/cupcakes/{id}", ""))
+
+	// Act
+	err := AddLinksOn(registry.Clone(), cupcake{}, Self("/v2/cupcakes/{id}", ""))
+
+	// Assert
+	assert.ErrorIs(t, err, ErrDuplicateLink)
+}
+
+func TestLinkRegistry_Unregister_RemovesType(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, cupcake{}, Self("/cupcakes/{id}", ""))
+	RegisterOn(registry, bakery{}, Self("/bakeries/{id}", ""))
+
+	// Act
+	registry.Unregister(&cupcake{})
+
+	// Assert
+	assert.Nil(t, registry.Links(cupcake{}))
+	assert.NotNil(t, registry.Links(bakery{}))
+	assert.JSONEq(t, `{"id":1,"name":"","bakery":null}`, string(InjectLinks(registry, cupcake{ID: 1})))
+}
+
+func TestLinkRegistry_RemoveLink_RemovesAction(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, cupcake{}, Self("/cupcakes/{id}", ""), Index("/cupcakes", ""))
+
+	snapshot := registry.snapshot()
+
+	// Act
+	registry.RemoveLink(cupcake{}, "index")
+	registry.RemoveLink(cupcake{}, "unknown")
+	registry.RemoveLink(bakery{}, "self")
+
+	// Assert
+	assert.Equal(t, map[string]LinkInfo{"self": {Method: http.MethodGet, Href: "/cupcakes/{id}"}}, registry.Links(cupcake{}))
+	assert.Nil(t, registry.Links(bakery{}))
+	assert.Len(t, snapshot[reflect.TypeOf(cupcake{})], 2)
 }
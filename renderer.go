@@ -0,0 +1,109 @@
+package gohateoas
+
+import "sort"
+
+// Resource is a struct that was encountered while walking through an object, it is handed
+// to a LinkRenderer to write its links.
+type Resource struct {
+	// Object is the original struct
+	Object any
+
+	// Result is the decoded json of the object, renderers may modify it
+	Result map[string]any
+
+	// Links contains the registered links of the object with their tokens replaced
+	Links map[string]LinkInfo
+
+	// Registered is true if the type of the object is present in the registry
+	Registered bool
+
+	// Embedded contains the json keys of nested objects or slices that have links registered,
+	// these have already been rendered.
+	Embedded []string
+}
+
+// LinkRenderer decides where and how the links of a resource end up in the output. It is called
+// for every struct in the object, after its nested objects have been rendered.
+type LinkRenderer interface {
+	RenderLinks(resource *Resource)
+}
+
+// DocumentRenderer can be implemented by a LinkRenderer that needs to change the document as a whole
+// after all resources have been rendered, like wrapping it in another object.
+type DocumentRenderer interface {
+	RenderDocument(result any) any
+}
+
+// WithRenderer makes InjectLinksWith use the given renderer to write links, use this to
+// implement formats that are not supported out of the box.
+func WithRenderer(renderer LinkRenderer) InjectOption {
+	return func(config *injectConfig) {
+		config.renderer = renderer
+	}
+}
+
+// defaultLinkProperty is the property the links are written to by default
+const defaultLinkProperty = "_links"
+
+// PropertyRenderer writes the links of a resource to a property of that resource. Its zero
+// value is the default renderer, writing LinkInfo objects keyed by their action to _links.
+type PropertyRenderer struct {
+	// Property is the name of the property, defaults to _links
+	Property string
+
+	// AsArray writes the links as an array of LinkInfo objects with a rel property instead
+	AsArray bool
+}
+
+// relLink is a LinkInfo with its relation, used by PropertyRenderer
+type relLink struct {
+	Rel string `json:"rel"`
+	LinkInfo
+}
+
+// RenderLinks writes the links to the configured property
+func (p PropertyRenderer) RenderLinks(resource *Resource) {
+	if len(resource.Links) == 0 {
+		return
+	}
+
+	property := p.Property
+	if property == "" {
+		property = defaultLinkProperty
+	}
+
+	if !p.AsArray {
+		resource.Result[property] = resource.Links
+
+		return
+	}
+
+	actions := sortedActions(resource.Links)
+
+	links := make([]relLink, 0, len(actions))
+
+	for _, action := range actions {
+		linkInfo := resource.Links[action]
+
+		relation := linkInfo.Rel
+		if relation == "" {
+			relation = action
+		}
+
+		links = append(links, relLink{Rel: relation, LinkInfo: linkInfo})
+	}
+
+	resource.Result[property] = links
+}
+
+// sortedActions returns the actions of the links in alphabetical order, to make sure the output is always the same
+func sortedActions(links map[string]LinkInfo) []string {
+	actions := make([]string, 0, len(links))
+	for action := range links {
+		actions = append(actions, action)
+	}
+
+	sort.Strings(actions)
+
+	return actions
+}
@@ -0,0 +1,99 @@
+package gohateoas
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPropertyRenderer_WritesExpectedProperty(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		renderer PropertyRenderer
+		expected map[string]any
+	}{
+		"zero value": {
+			renderer: PropertyRenderer{},
+			expected: map[string]any{
+				"id": float64(5),
+				"_links": map[string]any{
+					"self": map[string]any{"method": "GET", "href": "/api/v1/bakeries/5", "comment": "get itself"},
+					"post": map[string]any{"method": "POST", "href": "/api/v1/bakeries", "comment": "create one"},
+				},
+			},
+		},
+		"custom property": {
+			renderer: PropertyRenderer{Property: "links"},
+			expected: map[string]any{
+				"id": float64(5),
+				"links": map[string]any{
+					"self": map[string]any{"method": "GET", "href": "/api/v1/bakeries/5", "comment": "get itself"},
+					"post": map[string]any{"method": "POST", "href": "/api/v1/bakeries", "comment": "create one"},
+				},
+			},
+		},
+		"as array": {
+			renderer: PropertyRenderer{Property: "links", AsArray: true},
+			expected: map[string]any{
+				"id": float64(5),
+				"links": []any{
+					map[string]any{"rel": "post", "method": "POST", "href": "/api/v1/bakeries", "comment": "create one"},
+					map[string]any{"rel": "self", "method": "GET", "href": "/api/v1/bakeries/5", "comment": "get itself"},
+				},
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+			RegisterOn(registry, &bakery{}, Self("/api/v1/bakeries/{id}", "get itself"), Post("/api/v1/bakeries", "create one"))
+
+			// Act
+			result := InjectLinksWith(registry, &bakery{ID: 5}, WithRenderer(testData.renderer))
+
+			// Assert
+			var mapResult map[string]any
+			_ = json.Unmarshal(result, &mapResult)
+			assert.Equal(t, testData.expected, mapResult)
+		})
+	}
+}
+
+// hrefCollector is a renderer that keeps links out of the body and wraps the document
+type hrefCollector struct {
+	hrefs []string
+}
+
+func (h *hrefCollector) RenderLinks(resource *Resource) {
+	for _, link := range resource.Links {
+		h.hrefs = append(h.hrefs, link.Href)
+	}
+}
+
+func (h *hrefCollector) RenderDocument(result any) any {
+	return map[string]any{"result": result}
+}
+
+func TestInjectLinksWith_UsesCustomRenderer(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, &bakery{}, Self("/api/v1/bakeries/{id}", "get itself"))
+	RegisterOn(registry, &cupcake{}, Self("/api/v1/cupcakes/{id}", "get itself"))
+
+	renderer := &hrefCollector{}
+
+	// Act
+	result := InjectLinksWith(registry, &bakery{ID: 5, Cupcake: &cupcake{ID: 3}}, WithRenderer(renderer))
+
+	// Assert
+	assert.JSONEq(t, `{"result": {"id": 5, "cupcake": {"id": 3, "name": "", "bakery": null}}}`, string(result))
+
+	// The nested object is rendered first
+	assert.Equal(t, []string{"/api/v1/cupcakes/3", "/api/v1/bakeries/5"}, renderer.hrefs)
+}
@@ -0,0 +1,210 @@
+package gohateoas
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// AsSiren renders registered structs as Siren (application/vnd.siren+json) entities with a class,
+// properties, entities, links and actions. Links with the GET method end up in links, all others
+// are rendered as actions. Fields of actions are derived from the body set with WithRequestBody.
+// Nested objects that have links registered become sub-entities.
+func AsSiren() InjectOption {
+	return func(config *injectConfig) {
+		config.renderer = sirenRenderer{}
+	}
+}
+
+// sirenLink is a link as described in the Siren specification
+type sirenLink struct {
+	Rel   []string `json:"rel"`
+	Href  string   `json:"href"`
+	Title string   `json:"title,omitempty"`
+	Type  string   `json:"type,omitempty"`
+}
+
+// sirenAction is an action as described in the Siren specification
+type sirenAction struct {
+	Name   string       `json:"name"`
+	Title  string       `json:"title,omitempty"`
+	Method string       `json:"method"`
+	Href   string       `json:"href"`
+	Type   string       `json:"type,omitempty"`
+	Fields []sirenField `json:"fields,omitempty"`
+}
+
+// sirenField is a field of an action as described in the Siren specification
+type sirenField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// sirenRenderer renders resources according to the Siren specification
+type sirenRenderer struct{}
+
+func (sirenRenderer) RenderLinks(resource *Resource) {
+	if !resource.Registered {
+		return
+	}
+
+	entity := map[string]any{
+		"class": []string{resourceTypeOf(resource.Object)},
+	}
+
+	var entities []any
+
+	for _, jsonKey := range resource.Embedded {
+		switch value := resource.Result[jsonKey].(type) {
+		case map[string]any:
+			value["rel"] = []string{jsonKey}
+			entities = append(entities, value)
+
+		case []any:
+			for _, entry := range value {
+				if entry, ok := entry.(map[string]any); ok {
+					entry["rel"] = []string{jsonKey}
+					entities = append(entities, entry)
+				}
+			}
+		}
+
+		delete(resource.Result, jsonKey)
+	}
+
+	if len(entities) > 0 {
+		entity["entities"] = entities
+	}
+
+	links, actions := sirenLinksOf(resource.Links)
+
+	if len(links) > 0 {
+		entity["links"] = links
+	}
+
+	if len(actions) > 0 {
+		entity["actions"] = actions
+	}
+
+	properties := make(map[string]any, len(resource.Result))
+
+	// Replace the contents of the original map, that way the parent's reference stays intact
+	for key, value := range resource.Result {
+		properties[key] = value
+		delete(resource.Result, key)
+	}
+
+	entity["properties"] = properties
+
+	for key, value := range entity {
+		resource.Result[key] = value
+	}
+}
+
+// sirenLinksOf splits the links into links to follow and actions to perform
+func sirenLinksOf(links map[string]LinkInfo) ([]sirenLink, []sirenAction) {
+	var sirenLinks []sirenLink
+
+	var sirenActions []sirenAction
+
+	for _, name := range sortedActions(links) {
+		linkInfo := links[name]
+
+		if linkInfo.Method == "" || linkInfo.Method == http.MethodGet {
+			relation := linkInfo.Rel
+			if relation == "" {
+				relation = name
+			}
+
+			sirenLinks = append(sirenLinks, sirenLink{
+				Rel:   []string{relation},
+				Href:  linkInfo.Href,
+				Title: linkInfo.Comment,
+				Type:  linkInfo.Type,
+			})
+
+			continue
+		}
+
+		action := sirenAction{
+			Name:   name,
+			Title:  linkInfo.Comment,
+			Method: linkInfo.Method,
+			Href:   linkInfo.Href,
+			Type:   linkInfo.Type,
+		}
+
+		if linkInfo.RequestBody != nil {
+			action.Fields = sirenFieldsOf(reflect.TypeOf(linkInfo.RequestBody))
+
+			if action.Type == "" {
+				action.Type = "application/json"
+			}
+		}
+
+		sirenActions = append(sirenActions, action)
+	}
+
+	return sirenLinks, sirenActions
+}
+
+// timeType is used to recognise time fields in request bodies
+var timeType = reflect.TypeOf(time.Time{})
+
+// sirenFieldsOf derives action fields from the json fields of a struct
+func sirenFieldsOf(typeInfo reflect.Type) []sirenField {
+	typeInfo = ensureConcrete(typeInfo)
+	if typeInfo.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []sirenField
+
+	for i := 0; i < typeInfo.NumField(); i++ {
+		field := typeInfo.Field(i)
+		jsonKey := strings.Split(field.Tag.Get("json"), ",")[0]
+
+		// Embedded structs without a json name are flattened, like encoding/json does
+		if field.Anonymous && jsonKey == "" {
+			fields = append(fields, sirenFieldsOf(field.Type)...)
+
+			continue
+		}
+
+		if !field.IsExported() || jsonKey == "-" {
+			continue
+		}
+
+		if jsonKey == "" {
+			jsonKey = field.Name
+		}
+
+		fields = append(fields, sirenField{Name: jsonKey, Type: sirenFieldTypeOf(field.Type)})
+	}
+
+	return fields
+}
+
+// sirenFieldTypeOf returns the html5 input type that matches the go type
+func sirenFieldTypeOf(typeInfo reflect.Type) string {
+	typeInfo = ensureConcrete(typeInfo)
+
+	if typeInfo == timeType {
+		return "datetime-local"
+	}
+
+	//nolint:exhaustive // Everything else is text
+	switch typeInfo.Kind() {
+	case reflect.Bool:
+		return "checkbox"
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+
+	default:
+		return "text"
+	}
+}
@@ -0,0 +1,119 @@
+package gohateoas
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sirenCupcakeInput struct {
+	sirenBase
+
+	Name     string    `json:"name"`
+	Weight   float64   `json:"weight,omitempty"`
+	Vegan    *bool     `json:"vegan"`
+	BakedAt  time.Time `json:"bakedAt"`
+	Secret   string    `json:"-"`
+	Untagged string
+	internal string //nolint:unused // Makes sure unexported fields are ignored
+}
+
+type sirenBase struct {
+	Version int `json:"version"`
+}
+
+func TestSirenFieldsOf_ReturnsExpectedFields(t *testing.T) {
+	t.Parallel()
+	// Act
+	result := sirenFieldsOf(reflect.TypeOf(&sirenCupcakeInput{}))
+
+	// Assert
+	expected := []sirenField{
+		{Name: "version", Type: "number"},
+		{Name: "name", Type: "text"},
+		{Name: "weight", Type: "number"},
+		{Name: "vegan", Type: "checkbox"},
+		{Name: "bakedAt", Type: "datetime-local"},
+		{Name: "Untagged", Type: "text"},
+	}
+
+	assert.Equal(t, expected, result)
+}
+
+func TestSirenFieldsOf_ReturnsNilOnNonStruct(t *testing.T) {
+	t.Parallel()
+	// Act
+	result := sirenFieldsOf(reflect.TypeOf([]string{}))
+
+	// Assert
+	assert.Nil(t, result)
+}
+
+func TestInjectLinksWith_AsSirenCreatesExpectedJson(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+
+	RegisterOn(registry, &cupcake{},
+		Self("/api/v1/cupcakes/{id}", "get itself"),
+		Delete("/api/v1/cupcakes/{id}", "delete it"))
+
+	RegisterOn(registry, &bakery{},
+		Self("/api/v1/bakeries/{id}", "get a bakery by id"),
+		WithRequestBody(sirenCupcakeInput{}, Post("/api/v1/bakeries/{id}/cupcakes", "bake a cupcake")))
+
+	input := &bakery{
+		ID:       234,
+		Cupcakes: []*cupcake{{ID: 1, Name: "a"}},
+	}
+
+	// Act
+	result := InjectLinksWith(registry, input, AsSiren())
+
+	// Assert
+	expected := map[string]any{
+		"class":      []any{"bakery"},
+		"properties": map[string]any{"id": float64(234)},
+		"entities": []any{
+			map[string]any{
+				"class":      []any{"cupcake"},
+				"rel":        []any{"cupcakes"},
+				"properties": map[string]any{"id": float64(1), "name": "a", "bakery": nil},
+				"links": []any{
+					map[string]any{"rel": []any{"self"}, "href": "/api/v1/cupcakes/1", "title": "get itself"},
+				},
+				"actions": []any{
+					map[string]any{"name": "delete", "title": "delete it", "method": http.MethodDelete, "href": "/api/v1/cupcakes/1"},
+				},
+			},
+		},
+		"links": []any{
+			map[string]any{"rel": []any{"self"}, "href": "/api/v1/bakeries/234", "title": "get a bakery by id"},
+		},
+		"actions": []any{
+			map[string]any{
+				"name":   "post",
+				"title":  "bake a cupcake",
+				"method": http.MethodPost,
+				"href":   "/api/v1/bakeries/234/cupcakes",
+				"type":   "application/json",
+				"fields": []any{
+					map[string]any{"name": "version", "type": "number"},
+					map[string]any{"name": "name", "type": "text"},
+					map[string]any{"name": "weight", "type": "number"},
+					map[string]any{"name": "vegan", "type": "checkbox"},
+					map[string]any{"name": "bakedAt", "type": "datetime-local"},
+					map[string]any{"name": "Untagged", "type": "text"},
+				},
+			},
+		},
+	}
+
+	var mapResult map[string]any
+	_ = json.Unmarshal(result, &mapResult)
+	assert.Equal(t, expected, mapResult)
+}
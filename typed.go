@@ -0,0 +1,107 @@
+package gohateoas
+
+import "net/http"
+
+// LinkOptionFor is a LinkOption that can only be registered on T using RegisterFor, this way the
+// compiler checks that typed options like SelfFunc receive the right type. It can be converted to
+// a LinkOption to use it with RegisterOn.
+type LinkOptionFor[T any] func(map[string]LinkInfo)
+
+// RegisterFor registers links to T in the given registry, like RegisterOn does.
+func RegisterFor[T any](linkRegistry *LinkRegistry, options ...LinkOptionFor[T]) {
+	linkOptions := make([]LinkOption, 0, len(options))
+	for _, option := range options {
+		linkOptions = append(linkOptions, LinkOption(option))
+	}
+
+	var object T
+
+	RegisterOn(linkRegistry, object, linkOptions...)
+}
+
+// AddLinksFor adds links to T in the given registry, keeping the links that are already registered on it,
+// like AddLinksOn does.
+func AddLinksFor[T any](linkRegistry *LinkRegistry, options ...LinkOptionFor[T]) error {
+	linkOptions := make([]LinkOption, 0, len(options))
+	for _, option := range options {
+		linkOptions = append(linkOptions, LinkOption(option))
+	}
+
+	var object T
+
+	return AddLinksOn(linkRegistry, object, linkOptions...)
+}
+
+// Templates allows the existing options like Self or Custom to be used with RegisterFor.
+func Templates[T any](options ...LinkOption) LinkOptionFor[T] {
+	return func(registry map[string]LinkInfo) {
+		for _, option := range options {
+			option(registry)
+		}
+	}
+}
+
+// CustomFunc adds a link under the given action that is computed using the object, the link is left
+// out if the function returns false. The href is used as it is, it is not expanded like a template.
+func CustomFunc[T any](action string, compute func(object T) (LinkInfo, bool)) LinkOptionFor[T] {
+	return LinkOptionFor[T](computedLink(action, LinkInfo{}, compute))
+}
+
+// SelfFunc adds the self url of an object, computed using the object.
+func SelfFunc[T any](href func(object T) string, comment string) LinkOptionFor[T] {
+	return hrefFunc("self", http.MethodGet, href, comment)
+}
+
+// IndexFunc adds a general Index route, computed using the object.
+func IndexFunc[T any](href func(object T) string, comment string) LinkOptionFor[T] {
+	return hrefFunc("index", http.MethodGet, href, comment)
+}
+
+// PostFunc adds a general POST route, computed using the object.
+func PostFunc[T any](href func(object T) string, comment string) LinkOptionFor[T] {
+	return hrefFunc("post", http.MethodPost, href, comment)
+}
+
+// PutFunc adds a general PUT route, computed using the object.
+func PutFunc[T any](href func(object T) string, comment string) LinkOptionFor[T] {
+	return hrefFunc("put", http.MethodPut, href, comment)
+}
+
+// PatchFunc adds a general PATCH route, computed using the object.
+func PatchFunc[T any](href func(object T) string, comment string) LinkOptionFor[T] {
+	return hrefFunc("patch", http.MethodPatch, href, comment)
+}
+
+// DeleteFunc adds a general DELETE route, computed using the object.
+func DeleteFunc[T any](href func(object T) string, comment string) LinkOptionFor[T] {
+	return hrefFunc("delete", http.MethodDelete, href, comment)
+}
+
+// hrefFunc adds a link of which only the href is computed
+func hrefFunc[T any](action string, method string, href func(object T) string, comment string) LinkOptionFor[T] {
+	linkInfo := LinkInfo{Method: method, Comment: comment}
+
+	return LinkOptionFor[T](computedLink(action, linkInfo, func(object T) (LinkInfo, bool) {
+		computed := linkInfo
+		computed.Href = href(object)
+
+		return computed, true
+	}))
+}
+
+// computedLink registers the link under the given action, its contents are replaced by the result of
+// compute when links are injected. Objects that can't be converted to T don't get the link.
+func computedLink[T any](action string, linkInfo LinkInfo, compute func(object T) (LinkInfo, bool)) LinkOption {
+	return func(registry map[string]LinkInfo) {
+		linkInfo.compute = func(object any) (LinkInfo, bool) {
+			typedObject, ok := objectAs[T](object)
+			if !ok {
+				return LinkInfo{}, false
+			}
+
+			return compute(typedObject)
+		}
+
+		registry[action] = linkInfo
+	}
+}
@@ -0,0 +1,126 @@
+package gohateoas
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterFor_RegistersOnType(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+
+	// Act
+	RegisterFor[cupcake](registry, Templates[cupcake](Self("/cupcakes/{id}", "Get this cupcake")))
+
+	// Assert
+	expected := map[string]LinkInfo{
+		"self": {Method: http.MethodGet, Href: "/cupcakes/{id}", Comment: "Get this cupcake"},
+	}
+
+	assert.Equal(t, expected, registry.Links(cupcake{}))
+}
+
+func TestRegisterFor_ComputesTypedLinks(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		object   any
+		expected string
+	}{
+		"value": {
+			object: cupcake{ID: 3, Name: "red velvet"},
+			expected: `{"id":3,"name":"red velvet","bakery":null,"_links":{
+				"self":{"method":"GET","href":"/cupcakes/3","comment":"Get this cupcake"},
+				"index":{"method":"GET","href":"/cupcakes","comment":""},
+				"post":{"method":"POST","href":"/cupcakes/3/copies","comment":""},
+				"put":{"method":"PUT","href":"/cupcakes/3","comment":""},
+				"patch":{"method":"PATCH","href":"/cupcakes/3","comment":""},
+				"delete":{"method":"DELETE","href":"/cupcakes/3","comment":""},
+				"search":{"method":"GET","href":"/search/red velvet","comment":"","type":"text/html"}
+			}}`,
+		},
+		"pointer": {
+			object: &cupcake{ID: 4},
+			expected: `{"id":4,"name":"","bakery":null,"_links":{
+				"self":{"method":"GET","href":"/cupcakes/4","comment":"Get this cupcake"},
+				"index":{"method":"GET","href":"/cupcakes","comment":""},
+				"post":{"method":"POST","href":"/cupcakes/4/copies","comment":""},
+				"put":{"method":"PUT","href":"/cupcakes/4","comment":""},
+				"patch":{"method":"PATCH","href":"/cupcakes/4","comment":""},
+				"delete":{"method":"DELETE","href":"/cupcakes/4","comment":""}
+			}}`,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+
+			href := func(c cupcake) string { return fmt.Sprintf("/cupcakes/%d", c.ID) }
+
+			RegisterFor(registry,
+				SelfFunc(href, "Get this cupcake"),
+				IndexFunc(func(cupcake) string { return "/cupcakes" }, ""),
+				PostFunc(func(c cupcake) string { return href(c) + "/copies" }, ""),
+				PutFunc(href, ""),
+				PatchFunc(href, ""),
+				DeleteFunc(href, ""),
+				CustomFunc("search", func(c cupcake) (LinkInfo, bool) {
+					return LinkInfo{Method: http.MethodGet, Href: "/search/" + c.Name, Type: "text/html"}, c.Name != ""
+				}))
+
+			// Act
+			result := InjectLinks(registry, testData.object)
+
+			// Assert
+			assert.JSONEq(t, testData.expected, string(result))
+		})
+	}
+}
+
+func TestRegisterFor_CombinesTypedAndTemplateLinks(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+
+	RegisterFor(registry,
+		SelfFunc(func(b bakery) string { return fmt.Sprintf("/bakeries/%d", b.ID) }, ""),
+		Templates[bakery](Index("/bakeries", ""), When(func(b bakery) bool { return b.ID > 1 }, Delete("/bakeries/{id}", ""))))
+
+	// Act
+	result := InjectLinksWith(registry, []bakery{{ID: 1}, {ID: 2}}, WithBaseURL("https://example.com"))
+
+	// Assert
+	expected := `[
+		{"id":1,"_links":{
+			"self":{"method":"GET","href":"https://example.com/bakeries/1","comment":""},
+			"index":{"method":"GET","href":"https://example.com/bakeries","comment":""}
+		}},
+		{"id":2,"_links":{
+			"self":{"method":"GET","href":"https://example.com/bakeries/2","comment":""},
+			"index":{"method":"GET","href":"https://example.com/bakeries","comment":""},
+			"delete":{"method":"DELETE","href":"https://example.com/bakeries/2","comment":""}
+		}}
+	]`
+	assert.JSONEq(t, expected, string(result))
+}
+
+func TestLinkOptionFor_CanBeUsedAsLinkOption(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+
+	RegisterOn(registry, bakery{}, LinkOption(SelfFunc(func(b bakery) string { return fmt.Sprintf("/bakeries/%d", b.ID) }, "")))
+
+	// Act
+	result := InjectLinks(registry, bakery{ID: 5})
+
+	// Assert
+	assert.JSONEq(t, `{"id":5,"_links":{"self":{"method":"GET","href":"/bakeries/5","comment":""}}}`, string(result))
+}
@@ -0,0 +1,368 @@
+package gohateoas
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// templateOperator describes how the variables of an RFC 6570 expression are expanded
+type templateOperator struct {
+	first         string
+	separator     string
+	named         bool
+	ifEmpty       string
+	allowReserved bool
+
+	// optional form-style expressions, like {?page,size}, are meant to be expanded by the client if there are
+	// no values, their variables are never unresolved
+	optional bool
+
+	// preserveEncoded leaves percent-encoded triplets in values intact instead of encoding the %
+	preserveEncoded bool
+}
+
+// templateOperators contains all operators of RFC 6570 level 1 through 4, indexed by their prefix
+var templateOperators = map[byte]templateOperator{
+	'+': {first: "", separator: ",", allowReserved: true, preserveEncoded: true},
+	'#': {first: "#", separator: ",", allowReserved: true, preserveEncoded: true},
+	'.': {first: ".", separator: "."},
+	'/': {first: "/", separator: "/"},
+	';': {first: ";", separator: ";", named: true},
+	'?': {first: "?", separator: "&", named: true, ifEmpty: "=", optional: true},
+	'&': {first: "&", separator: "&", named: true, ifEmpty: "=", optional: true},
+}
+
+// simpleOperator is used for expressions without an operator, like {id}
+var simpleOperator = templateOperator{first: "", separator: ","}
+
+// varSpec is a single variable in an expression, like page in {?page,size} or name:3 in {name:3}
+type varSpec struct {
+	name      string
+	prefix    int
+	exploded  bool
+	validSpec bool
+}
+
+// parseExpression splits the contents of an expression into its operator and variables, returns
+// false if the operator is not supported.
+func parseExpression(expression string) (templateOperator, []varSpec, bool) {
+	operator := simpleOperator
+
+	if expression != "" {
+		if foundOperator, ok := templateOperators[expression[0]]; ok {
+			operator = foundOperator
+			expression = expression[1:]
+		} else if strings.IndexByte("=,!@|", expression[0]) >= 0 {
+			// Reserved for future extensions of the specification
+			return operator, nil, false
+		}
+	}
+
+	rawSpecs := strings.Split(expression, ",")
+	specs := make([]varSpec, 0, len(rawSpecs))
+
+	for _, rawSpec := range rawSpecs {
+		spec := varSpec{name: rawSpec, validSpec: rawSpec != ""}
+
+		if strings.HasSuffix(rawSpec, "*") {
+			spec.name, spec.exploded = strings.TrimSuffix(rawSpec, "*"), true
+		} else if index := strings.IndexByte(rawSpec, ':'); index >= 0 {
+			prefix, err := strconv.Atoi(rawSpec[index+1:])
+
+			spec.name, spec.prefix = rawSpec[:index], prefix
+			spec.validSpec = err == nil && prefix > 0 && prefix < 10000
+		}
+
+		specs = append(specs, spec)
+	}
+
+	return operator, specs, true
+}
+
+// requiredTemplateVariables returns the names of the variables in the template that must have a value,
+// which are all variables except those of optional expressions like {?page,size}.
+func requiredTemplateVariables(template string) []string {
+	var names []string
+
+	for _, match := range tokenReplaceRegex.FindAllStringSubmatch(template, -1) {
+		operator, specs, ok := parseExpression(match[1])
+		if !ok || operator.optional {
+			continue
+		}
+
+		for _, spec := range specs {
+			names = append(names, spec.name)
+		}
+	}
+
+	return names
+}
+
+// expandTemplate expands an RFC 6570 URI template, the values of variables are retrieved using lookup.
+// Variables that are found but null or empty are undefined and expand to nothing, as the specification
+// describes. Expressions of which none of the variables are found at all are left untouched, so they can
+// still be expanded by the client and the result is templated. The names of their variables are returned as
+// unresolved, unless the expression is optional like {?page,size}. If preserveEncoded is set, percent-encoded
+// triplets in values are not encoded again.
+func expandTemplate(template string, lookup func(name string) (any, bool), preserveEncoded bool) (string, []string, bool) {
+	var unresolved []string
+
+	templated := false
+
+	expanded := tokenReplaceRegex.ReplaceAllStringFunc(template, func(expression string) string {
+		operator, specs, ok := parseExpression(expression[1 : len(expression)-1])
+		if !ok {
+			return expression
+		}
+
+		operator.preserveEncoded = operator.preserveEncoded || preserveEncoded
+
+		var builder strings.Builder
+
+		found, defined := false, false
+
+		for _, spec := range specs {
+			value, ok := lookup(spec.name)
+			found = found || ok
+
+			if !ok || !spec.validSpec || isUndefined(value) {
+				continue
+			}
+
+			if defined {
+				builder.WriteString(operator.separator)
+			} else {
+				builder.WriteString(operator.first)
+				defined = true
+			}
+
+			expandValue(&builder, operator, spec, value)
+		}
+
+		if !found {
+			templated = true
+
+			if operator.optional {
+				return expression
+			}
+
+			for _, spec := range specs {
+				unresolved = append(unresolved, spec.name)
+			}
+
+			return expression
+		}
+
+		return builder.String()
+	})
+
+	return expanded, unresolved, templated
+}
+
+// isUndefined returns true if the value is null, an empty list or an empty map, as described in RFC 6570
+func isUndefined(value any) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+
+	case []any:
+		return len(value) == 0
+
+	case map[string]any:
+		return len(value) == 0
+
+	default:
+		return false
+	}
+}
+
+// expandValue writes the expansion of a single variable to the builder
+func expandValue(builder *strings.Builder, operator templateOperator, spec varSpec, value any) {
+	switch value := value.(type) {
+	case []any:
+		expandList(builder, operator, spec, value)
+
+	case map[string]any:
+		expandMap(builder, operator, spec, value)
+
+	default:
+		stringValue := templateString(value)
+
+		if operator.named {
+			writeNamed(builder, operator, spec.name, stringValue == "")
+		}
+
+		if spec.prefix > 0 && utf8.RuneCountInString(stringValue) > spec.prefix {
+			stringValue = string([]rune(stringValue)[:spec.prefix])
+		}
+
+		builder.WriteString(encodeTemplateValue(stringValue, operator))
+	}
+}
+
+// expandList writes the expansion of a list variable to the builder
+func expandList(builder *strings.Builder, operator templateOperator, spec varSpec, values []any) {
+	if !spec.exploded {
+		if operator.named {
+			writeNamed(builder, operator, spec.name, false)
+		}
+
+		for index, value := range values {
+			if index > 0 {
+				builder.WriteString(",")
+			}
+
+			builder.WriteString(encodeTemplateValue(templateString(value), operator))
+		}
+
+		return
+	}
+
+	for index, value := range values {
+		if index > 0 {
+			builder.WriteString(operator.separator)
+		}
+
+		stringValue := templateString(value)
+
+		if operator.named {
+			writeNamed(builder, operator, spec.name, stringValue == "")
+		}
+
+		builder.WriteString(encodeTemplateValue(stringValue, operator))
+	}
+}
+
+// expandMap writes the expansion of an associative array variable to the builder, keys are sorted
+// to make sure the result is always the same.
+func expandMap(builder *strings.Builder, operator templateOperator, spec varSpec, values map[string]any) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	if !spec.exploded && operator.named {
+		writeNamed(builder, operator, spec.name, false)
+	}
+
+	for index, key := range keys {
+		stringValue := templateString(values[key])
+		encodedKey := encodeTemplateValue(key, operator)
+		encodedValue := encodeTemplateValue(stringValue, operator)
+
+		if !spec.exploded {
+			if index > 0 {
+				builder.WriteString(",")
+			}
+
+			builder.WriteString(encodedKey + "," + encodedValue)
+
+			continue
+		}
+
+		if index > 0 {
+			builder.WriteString(operator.separator)
+		}
+
+		switch {
+		case operator.named && stringValue == "":
+			builder.WriteString(encodedKey + operator.ifEmpty)
+
+		default:
+			builder.WriteString(encodedKey + "=" + encodedValue)
+		}
+	}
+}
+
+// writeNamed writes the name of the variable followed by = or the ifEmpty value of the operator
+func writeNamed(builder *strings.Builder, operator templateOperator, name string, empty bool) {
+	builder.WriteString(name)
+
+	if empty {
+		builder.WriteString(operator.ifEmpty)
+
+		return
+	}
+
+	builder.WriteString("=")
+}
+
+// templateString converts a decoded json value to a string, numbers are never written in exponent notation
+func templateString(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+// reservedCharacters may be left unencoded by the + and # operators
+const reservedCharacters = ":/?#[]@!$&'()*+,;="
+
+// encodeTemplateValue percent-encodes every character that is not unreserved. Reserved characters are left
+// intact by operators that allow them, and existing percent-encoded triplets by operators that preserve them.
+// This makes sure values like a/b or a?b can't change the structure of the url, whether they're used in a
+// path segment or a query parameter.
+func encodeTemplateValue(value string, operator templateOperator) string {
+	var builder strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		character := value[i]
+
+		switch {
+		case isUnreserved(character):
+			builder.WriteByte(character)
+
+		case operator.allowReserved && strings.IndexByte(reservedCharacters, character) >= 0:
+			builder.WriteByte(character)
+
+		case operator.preserveEncoded && character == '%' && isPercentEncoded(value[i:]):
+			builder.WriteString(value[i : i+3])
+			i += 2
+
+		default:
+			_, _ = fmt.Fprintf(&builder, "%%%02X", character)
+		}
+	}
+
+	return builder.String()
+}
+
+// isUnreserved returns true if the character is an unreserved character as described in RFC 3986
+func isUnreserved(character byte) bool {
+	switch {
+	case 'a' <= character && character <= 'z', 'A' <= character && character <= 'Z', '0' <= character && character <= '9':
+		return true
+
+	default:
+		return character == '-' || character == '.' || character == '_' || character == '~'
+	}
+}
+
+// isPercentEncoded returns true if the value starts with a percent-encoded triplet, like %20
+func isPercentEncoded(value string) bool {
+	return len(value) >= 3 && value[0] == '%' && isHex(value[1]) && isHex(value[2])
+}
+
+// isHex returns true if the character is a hexadecimal digit
+func isHex(character byte) bool {
+	return ('0' <= character && character <= '9') || ('a' <= character && character <= 'f') || ('A' <= character && character <= 'F')
+}
+
+// PreserveEncodedValues leaves percent-encoded triplets like %20 in values intact, use this if the json fields
+// of your objects already contain encoded values. Other characters are still encoded.
+func PreserveEncodedValues() InjectOption {
+	return func(config *injectConfig) {
+		config.preserveEncodedValues = true
+	}
+}
@@ -0,0 +1,146 @@
+package gohateoas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// templateValues are the example variables of RFC 6570, as they would be decoded from json
+var templateValues = map[string]any{
+	"count":      []any{"one", "two", "three"},
+	"dom":        []any{"example", "com"},
+	"dub":        "me/too",
+	"hello":      "Hello World!",
+	"half":       "50%",
+	"var":        "value",
+	"who":        "fred",
+	"base":       "http://example.com/home/",
+	"path":       "/foo/bar",
+	"list":       []any{"red", "green", "blue"},
+	"keys":       map[string]any{"semi": ";", "dot": ".", "comma": ","},
+	"v":          "6",
+	"x":          1024.0,
+	"y":          768.0,
+	"empty":      "",
+	"empty_keys": map[string]any{},
+	"undef":      nil,
+	"big":        12345678.0,
+	"unicode":    "€",
+}
+
+func TestExpandTemplate_ReturnsExpectedHref(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		template   string
+		expected   string
+		unresolved []string
+		templated  bool
+	}{
+		// Level 1 and 2
+		"simple":                    {template: "{var}", expected: "value"},
+		"simple escaped":            {template: "{hello}", expected: "Hello%20World%21"},
+		"simple percent":            {template: "{half}", expected: "50%25"},
+		"simple empty":              {template: "O{empty}X", expected: "OX"},
+		"simple undefined":          {template: "O{undef}X", expected: "OX"},
+		"simple unicode":            {template: "{unicode}", expected: "%E2%82%AC"},
+		"simple number":             {template: "{big}", expected: "12345678"},
+		"reserved":                  {template: "{+var}", expected: "value"},
+		"reserved escaped":          {template: "{+hello}", expected: "Hello%20World!"},
+		"reserved percent":          {template: "{+half}", expected: "50%25"},
+		"reserved path":             {template: "{+path}/here", expected: "/foo/bar/here"},
+		"reserved query":            {template: "here?ref={+path}", expected: "here?ref=/foo/bar"},
+		"reserved base":             {template: "{+base}index", expected: "http://example.com/home/index"},
+		"fragment":                  {template: "X{#var}", expected: "X#value"},
+		"fragment escaped":          {template: "X{#hello}", expected: "X#Hello%20World!"},
+		"multiple variables":        {template: "map?{x,y}", expected: "map?1024,768"},
+		"multiple with empty":       {template: "{x,hello,y}", expected: "1024,Hello%20World%21,768"},
+		"reserved multiple":         {template: "{+path,x}/here", expected: "/foo/bar,1024/here"},
+		"fragment multiple":         {template: "{#path,x}/here", expected: "#/foo/bar,1024/here"},
+		"label":                     {template: "X{.var}", expected: "X.value"},
+		"label multiple":            {template: "X{.x,y}", expected: "X.1024.768"},
+		"path segment":              {template: "{/var}", expected: "/value"},
+		"path segment multiple":     {template: "{/var,x}/here", expected: "/value/1024/here"},
+		"path parameter":            {template: "{;x,y}", expected: ";x=1024;y=768"},
+		"path parameter empty":      {template: "{;x,y,empty}", expected: ";x=1024;y=768;empty"},
+		"query":                     {template: "{?x,y}", expected: "?x=1024&y=768"},
+		"query empty":               {template: "{?x,y,empty}", expected: "?x=1024&y=768&empty="},
+		"query undefined":           {template: "{?x,y,undef}", expected: "?x=1024&y=768"},
+		"query continuation":        {template: "?fixed=yes{&x}", expected: "?fixed=yes&x=1024"},
+		"query continuation many":   {template: "{&x,y,empty}", expected: "&x=1024&y=768&empty="},
+		"all undefined":             {template: "X{.empty_keys}", expected: "X"},
+		"query all undefined":       {template: "/a{?undef,empty_keys}", expected: "/a"},
+		"prefix":                    {template: "{var:3}", expected: "val"},
+		"prefix longer than value":  {template: "{var:30}", expected: "value"},
+		"prefix unicode":            {template: "{unicode:1}", expected: "%E2%82%AC"},
+		"reserved prefix":           {template: "{+path:6}/here", expected: "/foo/b/here"},
+		"fragment prefix":           {template: "{#path:6}/here", expected: "#/foo/b/here"},
+		"path segment prefix":       {template: "{/var:1,var}", expected: "/v/value"},
+		"query prefix":              {template: "{?var:3}", expected: "?var=val"},
+		"continuation prefix":       {template: "{&var:3}", expected: "&var=val"},
+		"list":                      {template: "{list}", expected: "red,green,blue"},
+		"list exploded":             {template: "{list*}", expected: "red,green,blue"},
+		"map":                       {template: "{keys}", expected: "comma,%2C,dot,.,semi,%3B"},
+		"map exploded":              {template: "{keys*}", expected: "comma=%2C,dot=.,semi=%3B"},
+		"reserved list":             {template: "{+list}", expected: "red,green,blue"},
+		"reserved map exploded":     {template: "{+keys*}", expected: "comma=,,dot=.,semi=;"},
+		"fragment list exploded":    {template: "{#list*}", expected: "#red,green,blue"},
+		"fragment map exploded":     {template: "{#keys*}", expected: "#comma=,,dot=.,semi=;"},
+		"label list":                {template: "X{.list}", expected: "X.red,green,blue"},
+		"label list exploded":       {template: "X{.list*}", expected: "X.red.green.blue"},
+		"label domain":              {template: "www{.dom*}", expected: "www.example.com"},
+		"label map exploded":        {template: "X{.keys*}", expected: "X.comma=%2C.dot=..semi=%3B"},
+		"path segment list":         {template: "{/list}", expected: "/red,green,blue"},
+		"path segment list explode": {template: "{/list*,path:4}", expected: "/red/green/blue/%2Ffoo"},
+		"path segment map exploded": {template: "{/keys*}", expected: "/comma=%2C/dot=./semi=%3B"},
+		"path parameter list":       {template: "{;list}", expected: ";list=red,green,blue"},
+		"path parameter exploded":   {template: "{;list*}", expected: ";list=red;list=green;list=blue"},
+		"path parameter map":        {template: "{;keys*}", expected: ";comma=%2C;dot=.;semi=%3B"},
+		"query list":                {template: "{?list}", expected: "?list=red,green,blue"},
+		"query list exploded":       {template: "{?list*}", expected: "?list=red&list=green&list=blue"},
+		"query map":                 {template: "{?keys}", expected: "?keys=comma,%2C,dot,.,semi,%3B"},
+		"query map exploded":        {template: "{?keys*}", expected: "?comma=%2C&dot=.&semi=%3B"},
+		"continuation list":         {template: "{&list*}", expected: "&list=red&list=green&list=blue"},
+
+		// Unresolved
+		"missing":              {template: "/a/{missing}", expected: "/a/{missing}", unresolved: []string{"missing"}, templated: true},
+		"missing query":        {template: "/a{?page,size}", expected: "/a{?page,size}", templated: true},
+		"missing continuation": {template: "/a?x=1{&page}", expected: "/a?x=1{&page}", templated: true},
+		"missing reserved":     {template: "{+root}/a", expected: "{+root}/a", unresolved: []string{"root"}, templated: true},
+		"missing fragment":     {template: "/a{#section}", expected: "/a{#section}", unresolved: []string{"section"}, templated: true},
+		"partially missing":    {template: "/a{?x,page}", expected: "/a?x=1024"},
+		"missing and resolved": {template: "/a/{var}{/missing}", expected: "/a/value{/missing}", unresolved: []string{"missing"}, templated: true},
+		"unsupported operator": {template: "/a/{=var}", expected: "/a/{=var}"},
+		"no expressions":       {template: "/a/b", expected: "/a/b"},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			lookup := func(name string) (any, bool) {
+				value, ok := templateValues[name]
+
+				return value, ok
+			}
+
+			// Act
+			result, unresolved, templated := expandTemplate(testData.template, lookup, false)
+
+			// Assert
+			assert.Equal(t, testData.expected, result)
+			assert.Equal(t, testData.unresolved, unresolved)
+			assert.Equal(t, testData.templated, templated)
+		})
+	}
+}
+
+func TestRequiredTemplateVariables_ReturnsRequiredVariables(t *testing.T) {
+	t.Parallel()
+	// Act
+	result := requiredTemplateVariables("/a/{id}{/path*}{?page,size:3}{&sort}{+base}{#section}{.ext}{=reserved}")
+
+	// Assert
+	assert.Equal(t, []string{"id", "path", "base", "section", "ext"}, result)
+}
@@ -0,0 +1,81 @@
+package gohateoas
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// variablePrefix is the prefix of template variables that refer to request-scoped variables, like {ctx.tenant}
+const variablePrefix = "ctx."
+
+// variablesContextKey is used to store variables in a context.Context
+type variablesContextKey struct{}
+
+// ContextWithVariable returns a copy of the context with the variable added, it can be referenced in
+// templates as {ctx.name} when using InjectLinksContext.
+func ContextWithVariable(ctx context.Context, name string, value any) context.Context {
+	return ContextWithVariables(ctx, map[string]any{name: value})
+}
+
+// ContextWithVariables returns a copy of the context with the variables added, they can be referenced in
+// templates as {ctx.name} when using InjectLinksContext. Existing variables with the same name are replaced.
+func ContextWithVariables(ctx context.Context, variables map[string]any) context.Context {
+	existing := VariablesFromContext(ctx)
+
+	merged := make(map[string]any, len(existing)+len(variables))
+	for name, value := range existing {
+		merged[name] = value
+	}
+
+	for name, value := range variables {
+		merged[name] = value
+	}
+
+	return context.WithValue(ctx, variablesContextKey{}, merged)
+}
+
+// VariablesFromContext returns the variables that were added to the context, or nil if there are none
+func VariablesFromContext(ctx context.Context) map[string]any {
+	variables, _ := ctx.Value(variablesContextKey{}).(map[string]any)
+
+	return variables
+}
+
+// WithVariables makes the variables available to templates as {ctx.name}, in addition to the variables in
+// the context of InjectLinksContext. Existing variables with the same name are replaced.
+func WithVariables(variables map[string]any) InjectOption {
+	normalized := normalizeVariables(variables)
+
+	return func(config *injectConfig) {
+		if config.variables == nil {
+			config.variables = make(map[string]any, len(normalized))
+		}
+
+		for name, value := range normalized {
+			config.variables[name] = value
+		}
+	}
+}
+
+// normalizeVariables converts the values to what they would look like when decoded from json, this
+// way slices, maps and structs can be expanded like json fields can. Values that can't be encoded are
+// used as they are.
+func normalizeVariables(variables map[string]any) map[string]any {
+	normalized := make(map[string]any, len(variables))
+
+	for name, value := range variables {
+		normalized[name] = value
+
+		rawJson, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+
+		var decoded any
+		if err := json.Unmarshal(rawJson, &decoded); err == nil {
+			normalized[name] = decoded
+		}
+	}
+
+	return normalized
+}
@@ -0,0 +1,126 @@
+package gohateoas
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextWithVariables_MergesVariables(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	ctx := ContextWithVariable(context.Background(), "tenant", "a")
+	ctx = ContextWithVariables(ctx, map[string]any{"version": 2, "tenant": "b"})
+
+	// Act
+	result := VariablesFromContext(ctx)
+
+	// Assert
+	assert.Equal(t, map[string]any{"tenant": "b", "version": 2}, result)
+}
+
+func TestContextWithVariables_DoesNotChangeParentContext(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	parent := ContextWithVariable(context.Background(), "tenant", "a")
+
+	// Act
+	_ = ContextWithVariable(parent, "tenant", "b")
+
+	// Assert
+	assert.Equal(t, map[string]any{"tenant": "a"}, VariablesFromContext(parent))
+}
+
+func TestVariablesFromContext_ReturnsNilWithoutVariables(t *testing.T) {
+	t.Parallel()
+	// Act
+	result := VariablesFromContext(context.Background())
+
+	// Assert
+	assert.Nil(t, result)
+}
+
+func TestInjectLinksContext_ResolvesVariables(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		ctx       context.Context
+		options   []InjectOption
+		href      string
+		expected  string
+		templated bool
+	}{
+		"context variable": {
+			ctx:      ContextWithVariable(context.Background(), "tenant", "acme"),
+			href:     "/{ctx.tenant}/cupcakes/{id}",
+			expected: "/acme/cupcakes/3",
+		},
+		"option variable": {
+			ctx:      context.Background(),
+			options:  []InjectOption{WithVariables(map[string]any{"version": 2})},
+			href:     "/api/v{ctx.version}/cupcakes/{id}",
+			expected: "/api/v2/cupcakes/3",
+		},
+		"option overrides context": {
+			ctx:      ContextWithVariable(context.Background(), "locale", "nl"),
+			options:  []InjectOption{WithVariables(map[string]any{"locale": "en"})},
+			href:     "/cupcakes/{id}{?ctx.locale}",
+			expected: "/cupcakes/3?ctx.locale=en",
+		},
+		"list variable": {
+			ctx:      ContextWithVariable(context.Background(), "roles", []string{"admin", "baker"}),
+			href:     "/cupcakes/{id}{/ctx.roles*}",
+			expected: "/cupcakes/3/admin/baker",
+		},
+		"struct variable": {
+			ctx:      ContextWithVariable(context.Background(), "user", struct{ ID int }{ID: 9}),
+			href:     "/users/{ctx.user.ID}/cupcakes/{id}",
+			expected: "/users/9/cupcakes/3",
+		},
+		"missing variable": {
+			ctx:       context.Background(),
+			href:      "/{ctx.tenant}/cupcakes/{id}",
+			expected:  "/{ctx.tenant}/cupcakes/3",
+			templated: true,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := NewLinkRegistry()
+			RegisterOn(registry, cupcake{}, Self(testData.href, ""))
+
+			// Act
+			result := InjectLinksContext(testData.ctx, registry, cupcake{ID: 3}, testData.options...)
+
+			// Assert
+			var decoded struct {
+				Links map[string]LinkInfo `json:"_links"`
+			}
+
+			_ = json.Unmarshal(result, &decoded)
+
+			expected := LinkInfo{Method: http.MethodGet, Href: testData.expected, Templated: testData.templated}
+			assert.Equal(t, expected, decoded.Links["self"])
+		})
+	}
+}
+
+func TestMarshalWithLinksContext_FailsOnMissingVariable(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := NewLinkRegistry()
+	RegisterOn(registry, cupcake{}, Self("/{ctx.tenant}/cupcakes/{id}", ""))
+
+	// Act
+	result, err := MarshalWithLinksContext(context.Background(), registry, cupcake{ID: 3})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrUnresolvedToken)
+}